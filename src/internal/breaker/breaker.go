@@ -0,0 +1,100 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen возвращается вместо обращения к ресурсу, пока предохранитель разомкнут
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Состояния предохранителя
+const (
+	StateClosed = "closed"
+	StateOpen   = "open"
+)
+
+// Breaker - предохранитель: после threshold ошибок подряд размыкается и перестает пропускать запросы к ресурсу.
+// Пока он разомкнут, раз в probeInterval вызывается probe, и после первой успешной проверки предохранитель замыкается
+type Breaker struct {
+	sync.Mutex
+	threshold     int
+	probeInterval time.Duration
+	probe         func() error
+	failures      int
+	open          bool
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+func New(threshold int, probeInterval time.Duration, probe func() error) *Breaker {
+	return &Breaker{
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		probe:         probe,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Allow сообщает, можно ли обращаться к ресурсу
+func (b *Breaker) Allow() bool {
+	b.Lock()
+	defer b.Unlock()
+	return !b.open
+}
+
+// Success сбрасывает счетчик ошибок после успешного обращения
+func (b *Breaker) Success() {
+	b.Lock()
+	defer b.Unlock()
+	b.failures = 0
+}
+
+// Failure учитывает ошибку ресурса и размыкает предохранитель, если ошибок подряд стало threshold
+func (b *Breaker) Failure() {
+	b.Lock()
+	defer b.Unlock()
+	if b.open {
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.open = true
+		go b.probing()
+	}
+}
+
+func (b *Breaker) State() string {
+	b.Lock()
+	defer b.Unlock()
+	if b.open {
+		return StateOpen
+	}
+	return StateClosed
+}
+
+// Stop останавливает проверку ресурса. Повторный вызов ничего не делает
+func (b *Breaker) Stop() {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+}
+
+// probing проверяет ресурс, пока предохранитель разомкнут
+func (b *Breaker) probing() {
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-time.After(b.probeInterval):
+		}
+		if b.probe() == nil {
+			b.Lock()
+			b.open = false
+			b.failures = 0
+			b.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package breaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerTripsAfterThreshold(t *testing.T) {
+	b := New(3, time.Hour, func() error { return nil })
+	defer b.Stop()
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	if !b.Allow() {
+		t.Fatal("breaker opened before threshold of consecutive failures")
+	}
+	b.Failure()
+	if b.Allow() || b.State() != StateOpen {
+		t.Fatalf("breaker state = %s after threshold; want open", b.State())
+	}
+}
+
+func TestBreakerClosesAfterSuccessfulProbe(t *testing.T) {
+	var healthy atomic.Bool
+	var probes atomic.Int32
+	b := New(1, time.Millisecond, func() error {
+		probes.Add(1)
+		if !healthy.Load() {
+			return errors.New("database is down")
+		}
+		return nil
+	})
+	defer b.Stop()
+	b.Failure()
+	for probes.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if b.Allow() {
+		t.Fatal("breaker closed after failed probe")
+	}
+	healthy.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for !b.Allow() {
+		if time.Now().After(deadline) {
+			t.Fatal("breaker did not close after successful probe")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBreakerStopTwice(t *testing.T) {
+	b := New(1, time.Hour, func() error { return nil })
+	b.Stop()
+	b.Stop()
+}
@@ -3,123 +3,104 @@ package cashe
 import (
 	"banner/models"
 	"os"
-	"slices"
 	"strconv"
-	"strings"
-	"sync"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
-type Cache struct {
-	sync.RWMutex
-	defaultExpiration time.Duration
-	cleanupInterval   time.Duration
-	Items             map[string]Item
+// Cache - кэш баннеров по паре фича-тэг. Реализации: MemoryCache (в памяти процесса)
+// и RedisCache (общий для всех реплик сервиса), выбирается переменной CACHE_BACKEND
+type Cache interface {
+	// AddOne кладет баннер под каждой парой FeatureID-TagIDs на время CACHE_EXPIRATION
+	AddOne(banner Item)
+	// Generation возвращает текущее значение счетчика инвалидаций
+	Generation() uint64
+	// AddOneIfGeneration добавляет элемент, только если после получения generation кэш не инвалидировался
+	AddOneIfGeneration(banner Item, generation uint64) bool
+	// Get возвращает содержимое баннера, признак того, что он доступен пользователю (nil, если баннера нет в кэше),
+	// и признак того, что элемент устарел (прошло CACHE_EXPIRATION, но не CACHE_HARD_EXPIRATION) и его нужно обновить
+	Get(feature, tag int32) (models.JSONMap, bool, bool)
+	// GetLastKnown возвращает элемент, даже если он устарел, пока он еще не удален из кэша.
+	// Используется, когда база данных недоступна
+	GetLastKnown(feature, tag int32) (models.JSONMap, bool)
+	// Remove удаляет из кэша все элементы баннера id
+	Remove(id int32)
+	// Clear удаляет из кэша все элементы
+	Clear()
+	// Hot возвращает до n пар фича-тэг, запрошенных последними (n <= 0 - все пары)
+	Hot(n int) []Key
+	Stats() Stats
+	Close() error
+}
+
+// Stats - заполненность кэша и счетчики удаленных элементов
+type Stats struct {
+	Entries int64
+	// Bytes - приблизительный суммарный размер содержимого элементов
+	Bytes int64
+	// Evictions - элементы, вытесненные при превышении CACHE_MAX_ENTRIES или CACHE_MAX_BYTES
+	Evictions int64
+	// Expired - элементы, удаленные по истечении CACHE_EXPIRATION
+	Expired int64
+}
+
+// Key - пара фича-тэг, по которой пользователь запрашивает баннер
+type Key struct {
+	Feature int32 `json:"feature_id"`
+	Tag     int32 `json:"tag_id"`
 }
 
 type Item struct {
-	BannerID  int32
-	FeatureID int32
-	TagIDs    []int32
-	IsActive  bool
+	BannerID  int32      `json:"banner_id"`
+	FeatureID int32      `json:"feature_id"`
+	TagIDs    []int32    `json:"tag_ids"`
+	IsActive  bool       `json:"is_active"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
 	//UpdatedAt string
 	//CreatedAt string
-	Content    models.JSONMap
-	Expiration time.Time
+	Content models.JSONMap `json:"content"`
+	// Expiration - момент, после которого элемент устарел, HardExpiration - после которого он не отдается
+	Expiration     time.Time `json:"expiration"`
+	HardExpiration time.Time `json:"-"`
 }
 
-func NewCache() *Cache {
-
-	items := make(map[string]Item)
+func NewCache() Cache {
 	exp, err := time.ParseDuration(os.Getenv("CACHE_EXPIRATION"))
 	if err != nil {
 		panic("Can't parse CACHE_EXPIRATION: " + err.Error())
 	}
-	clean, err := time.ParseDuration(os.Getenv("CACHE_CLEANUP_INTERVAL"))
-	if err != nil {
-		panic("Can't parse CACHE_CLEANUP_INTERVAL: " + err.Error())
-	}
-	cache := Cache{
-		Items:             items,
-		defaultExpiration: exp,
-		cleanupInterval:   clean,
-	}
-	// Если интервал очистки больше 0, запускаем GC (удаление устаревших элементов)
-	cache.startGC() // данный метод рассматривается ниже
-
-	return &cache
-}
-
-// AddOne element to cache
-func (c *Cache) AddOne(banner Item) {
-	c.Lock()
-	defer c.Unlock()
-	key := strconv.Itoa(int(banner.BannerID)) + "_" + strconv.Itoa(int(banner.FeatureID))
-	banner.Expiration = time.Now().Add(c.defaultExpiration)
-	c.Items[key] = banner
-}
-
-func (c *Cache) Get(feature, tag int32) (models.JSONMap, bool) {
-	c.RLock()
-	defer c.RUnlock()
-	for key, value := range c.Items {
-		parts := strings.Split(key, "_")
-		if f, _ := strconv.Atoi(parts[1]); f == int(feature) && slices.Contains(value.TagIDs, tag) {
-			if value.Expiration.Before(time.Now()) {
-				return nil, false
-			}
-			return value.Content, value.IsActive
+	// Режим stale-while-revalidate: до CACHE_HARD_EXPIRATION устаревший элемент отдается, пока он обновляется в фоне
+	hardExp := exp
+	if value := os.Getenv("CACHE_HARD_EXPIRATION"); value != "" {
+		hardExp, err = time.ParseDuration(value)
+		if err != nil {
+			panic("Can't parse CACHE_HARD_EXPIRATION: " + err.Error())
 		}
 	}
-	return nil, false
-}
-
-func (c *Cache) startGC() {
-	go c.gC()
-}
-
-func (c *Cache) gC() {
-	for {
-		// ожидаем время установленное в cleanupInterval
-		<-time.After(c.cleanupInterval)
-
-		if c.Items == nil {
-			return
+	switch backend := os.Getenv("CACHE_BACKEND"); backend {
+	case "", "memory":
+		return NewMemoryCache(exp, hardExp)
+	case "redis":
+		db := 0
+		if value := os.Getenv("REDIS_DB"); value != "" {
+			db, err = strconv.Atoi(value)
+			if err != nil {
+				panic("Can't parse REDIS_DB: " + err.Error())
+			}
 		}
-
-		// Ищем элементы с истекшим временем жизни и удаляем из хранилища
-		if keys := c.expiredKeys(); len(keys) != 0 {
-			c.clearItems(keys)
+		client := redis.NewClient(&redis.Options{
+			Addr:     os.Getenv("REDIS_ADDR"),
+			Password: os.Getenv("REDIS_PASSWORD"),
+			DB:       db,
+		})
+		cache, err := NewRedisCache(client, exp, hardExp)
+		if err != nil {
+			panic(err.Error())
 		}
-
-	}
-
-}
-
-// expiredKeys возвращает список "просроченных" ключей
-func (c *Cache) expiredKeys() map[string]interface{} {
-
-	c.RLock()
-	defer c.RUnlock()
-	res := make(map[string]interface{})
-	for key, value := range c.Items {
-		if time.Now().After(value.Expiration) {
-			res[key] = nil
-		}
-	}
-
-	return res
-}
-
-// clearItems удаляет ключи из переданного списка, в нашем случае "просроченные"
-func (c *Cache) clearItems(keys map[string]interface{}) {
-	c.Lock()
-
-	defer c.Unlock()
-	if len(keys) == 0 {
-		return
-	}
-	for key := range keys {
-		delete(c.Items, key)
+		return cache
+	default:
+		panic("Unknown CACHE_BACKEND: " + backend)
 	}
 }
@@ -0,0 +1,313 @@
+package cashe
+
+import (
+	"banner/models"
+	"container/list"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryCache - кэш внутри памяти процесса. У каждой реплики сервиса он свой
+type MemoryCache struct {
+	sync.RWMutex
+	defaultExpiration time.Duration
+	// hardExpiration - время жизни устаревшего элемента, если оно больше defaultExpiration
+	hardExpiration  time.Duration
+	cleanupInterval time.Duration
+	// Items - индекс по паре фича-тэг, поиск баннера за O(1)
+	Items map[Key]Item
+	// banners - обратный индекс: id баннера -> пары фича-тэг, под которыми он лежит в Items
+	banners map[int32]map[Key]struct{}
+	// generation увеличивается при каждой инвалидации, см. AddOneIfGeneration
+	generation uint64
+
+	// maxEntries и maxBytes ограничивают количество элементов и суммарный размер их содержимого (0 - без ограничения).
+	// При превышении вытесняются давно не запрашивавшиеся элементы
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	// lru - элементы от последнего перенесенного в начало к давно не запрашивавшемуся. Get не переносит элементы,
+	// а только отмечает их (lruEntry.touched), порядок уточняется при вытеснении
+	lru      *list.List
+	elements map[Key]*list.Element
+	// evictions - элементы, вытесненные из-за ограничений, expired - удаленные по истечении времени жизни
+	evictions int64
+	expired   int64
+}
+
+// lruEntry - элемент списка lru
+type lruEntry struct {
+	key  Key
+	size int64
+	// touched - элемент запрашивался после последнего переноса в начало lru. Отмечается под блокировкой на чтение
+	touched atomic.Bool
+}
+
+func NewMemoryCache(exp, hardExp time.Duration) *MemoryCache {
+	clean, err := time.ParseDuration(os.Getenv("CACHE_CLEANUP_INTERVAL"))
+	if err != nil {
+		panic("Can't parse CACHE_CLEANUP_INTERVAL: " + err.Error())
+	}
+	cache := newMemoryCache(exp, clean)
+	cache.hardExpiration = hardExp
+	if value := os.Getenv("CACHE_MAX_ENTRIES"); value != "" {
+		cache.maxEntries, err = strconv.Atoi(value)
+		if err != nil {
+			panic("Can't parse CACHE_MAX_ENTRIES: " + err.Error())
+		}
+	}
+	if value := os.Getenv("CACHE_MAX_BYTES"); value != "" {
+		cache.maxBytes, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			panic("Can't parse CACHE_MAX_BYTES: " + err.Error())
+		}
+	}
+	return cache
+}
+
+func newMemoryCache(exp, clean time.Duration) *MemoryCache {
+	cache := MemoryCache{
+		Items:             make(map[Key]Item),
+		banners:           make(map[int32]map[Key]struct{}),
+		lru:               list.New(),
+		elements:          make(map[Key]*list.Element),
+		defaultExpiration: exp,
+		cleanupInterval:   clean,
+	}
+	// Если интервал очистки больше 0, запускаем GC (удаление устаревших элементов)
+	cache.startGC() // данный метод рассматривается ниже
+
+	return &cache
+}
+
+// AddOne element to cache. Баннер кладется под каждой парой FeatureID-TagIDs
+func (c *MemoryCache) AddOne(banner Item) {
+	c.Lock()
+	defer c.Unlock()
+	c.addOne(banner)
+}
+
+// Generation возвращает текущее значение счетчика инвалидаций
+func (c *MemoryCache) Generation() uint64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.generation
+}
+
+// AddOneIfGeneration добавляет элемент, только если после получения generation кэш не инвалидировался.
+// Так данные, прочитанные из базы до изменения баннера, не попадут в кэш после его инвалидации
+func (c *MemoryCache) AddOneIfGeneration(banner Item, generation uint64) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.generation != generation {
+		return false
+	}
+	c.addOne(banner)
+	return true
+}
+
+func (c *MemoryCache) addOne(banner Item) {
+	banner.Expiration = time.Now().Add(c.defaultExpiration)
+	banner.HardExpiration = time.Now().Add(max(c.defaultExpiration, c.hardExpiration))
+	size := contentSize(banner.Content)
+	for _, tag := range banner.TagIDs {
+		key := Key{Feature: banner.FeatureID, Tag: tag}
+		c.removeKey(key)
+		c.Items[key] = banner
+		c.elements[key] = c.lru.PushFront(&lruEntry{key: key, size: size})
+		c.bytes += size
+		keys, ok := c.banners[banner.BannerID]
+		if !ok {
+			keys = make(map[Key]struct{})
+			c.banners[banner.BannerID] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	c.evict()
+}
+
+// evict вытесняет давно не запрашивавшиеся элементы, пока кэш не уложится в ограничения. Отмеченный элемент
+// из конца lru не вытесняется, а переносится в начало (второй шанс). Вызывается под блокировкой
+func (c *MemoryCache) evict() {
+	for c.lru.Len() > 0 && (c.maxEntries > 0 && len(c.Items) > c.maxEntries || c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		element := c.lru.Back()
+		entry := element.Value.(*lruEntry)
+		if entry.touched.Swap(false) {
+			c.lru.MoveToFront(element)
+			continue
+		}
+		c.removeKey(entry.key)
+		c.evictions++
+	}
+}
+
+// contentSize - приблизительный размер содержимого баннера в байтах (длина JSON)
+func contentSize(content models.JSONMap) int64 {
+	bytes, err := json.Marshal(content)
+	if err != nil {
+		return 0
+	}
+	return int64(len(bytes))
+}
+
+// Get блокирует кэш только на чтение: найденный элемент не переносится в начало списка lru, а отмечается
+func (c *MemoryCache) Get(feature, tag int32) (models.JSONMap, bool, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	key := Key{Feature: feature, Tag: tag}
+	value, ok := c.Items[key]
+	now := time.Now()
+	if !ok || value.HardExpiration.Before(now) {
+		return nil, false, false
+	}
+	// повторная отметка не пишет в память, чтобы частые запросы одного элемента не конкурировали за нее
+	if entry := c.elements[key].Value.(*lruEntry); !entry.touched.Load() {
+		entry.touched.Store(true)
+	}
+	return value.Content, value.IsActive && models.InWindow(value.StartsAt, value.EndsAt, now), value.Expiration.Before(now)
+}
+
+func (c *MemoryCache) GetLastKnown(feature, tag int32) (models.JSONMap, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	value, ok := c.Items[Key{Feature: feature, Tag: tag}]
+	if !ok {
+		return nil, false
+	}
+	return value.Content, value.IsActive && models.InWindow(value.StartsAt, value.EndsAt, time.Now())
+}
+
+// Remove удаляет из кэша все элементы баннера id
+func (c *MemoryCache) Remove(id int32) {
+	c.Lock()
+	defer c.Unlock()
+	c.generation++
+	for key := range c.banners[id] {
+		c.removeKey(key)
+	}
+}
+
+func (c *MemoryCache) Clear() {
+	c.Lock()
+	defer c.Unlock()
+	c.generation++
+	c.Items = make(map[Key]Item)
+	c.banners = make(map[int32]map[Key]struct{})
+	c.lru.Init()
+	c.elements = make(map[Key]*list.Element)
+	c.bytes = 0
+}
+
+// removeKey удаляет элемент из обоих индексов. Вызывается под блокировкой
+func (c *MemoryCache) removeKey(key Key) {
+	item, ok := c.Items[key]
+	if !ok {
+		return
+	}
+	delete(c.Items, key)
+	if element, ok := c.elements[key]; ok {
+		c.bytes -= element.Value.(*lruEntry).size
+		c.lru.Remove(element)
+		delete(c.elements, key)
+	}
+	if keys, ok := c.banners[item.BannerID]; ok {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.banners, item.BannerID)
+		}
+	}
+}
+
+// Hot возвращает n последних запрошенных пар: сначала отмеченные элементы, затем остальные в порядке lru
+func (c *MemoryCache) Hot(n int) []Key {
+	c.RLock()
+	defer c.RUnlock()
+	if n <= 0 || n > c.lru.Len() {
+		n = c.lru.Len()
+	}
+	res := make([]Key, 0, n)
+	for _, touched := range []bool{true, false} {
+		for element := c.lru.Front(); element != nil && len(res) < n; element = element.Next() {
+			if entry := element.Value.(*lruEntry); entry.touched.Load() == touched {
+				res = append(res, entry.key)
+			}
+		}
+	}
+	return res
+}
+
+func (c *MemoryCache) Stats() Stats {
+	c.RLock()
+	defer c.RUnlock()
+	return Stats{
+		Entries:   int64(len(c.Items)),
+		Bytes:     c.bytes,
+		Evictions: c.evictions,
+		Expired:   c.expired,
+	}
+}
+
+// Close ничего не делает: кэш в памяти не держит внешних ресурсов
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+func (c *MemoryCache) startGC() {
+	go c.gC()
+}
+
+func (c *MemoryCache) gC() {
+	for {
+		// ожидаем время установленное в cleanupInterval
+		<-time.After(c.cleanupInterval)
+
+		if c.Items == nil {
+			return
+		}
+
+		// Ищем элементы с истекшим временем жизни и удаляем из хранилища
+		if keys := c.expiredKeys(); len(keys) != 0 {
+			c.clearItems(keys)
+		}
+
+	}
+
+}
+
+// expiredKeys возвращает список "просроченных" ключей
+func (c *MemoryCache) expiredKeys() []Key {
+
+	c.RLock()
+	defer c.RUnlock()
+	res := make([]Key, 0)
+	now := time.Now()
+	for key, value := range c.Items {
+		if now.After(value.HardExpiration) {
+			res = append(res, key)
+		}
+	}
+
+	return res
+}
+
+// clearItems удаляет ключи из переданного списка, в нашем случае "просроченные"
+func (c *MemoryCache) clearItems(keys []Key) {
+	c.Lock()
+
+	defer c.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, key := range keys {
+		// элемент мог быть обновлен после поиска просроченных ключей
+		if item, ok := c.Items[key]; ok && now.After(item.HardExpiration) {
+			c.removeKey(key)
+			c.expired++
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package cashe
+
+import (
+	"banner/models"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const benchEntries = 100_000
+
+func TestCacheGetAndRemove(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1, 2}, IsActive: true, Content: models.JSONMap{"title": "a"}})
+	c.AddOne(Item{BannerID: 2, FeatureID: 10, TagIDs: []int32{3}, Content: models.JSONMap{"title": "b"}})
+
+	if content, active, _ := c.Get(10, 2); content["title"] != "a" || !active {
+		t.Fatalf("Get(10, 2) = %v, %v; want banner 1", content, active)
+	}
+	if content, active, _ := c.Get(10, 3); content["title"] != "b" || active {
+		t.Fatalf("Get(10, 3) = %v, %v; want inactive banner 2", content, active)
+	}
+
+	c.Remove(1)
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get(10, 1) after Remove(1) = %v; want nil", content)
+	}
+	if content, _, _ := c.Get(10, 3); content == nil {
+		t.Fatal("Remove(1) removed banner 2")
+	}
+	if _, ok := c.banners[1]; ok {
+		t.Fatal("reverse index still contains banner 1")
+	}
+}
+
+func TestCacheAddOneReplacesPair(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	c.AddOne(Item{BannerID: 2, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "b"}})
+
+	if content, _, _ := c.Get(10, 1); content["title"] != "b" {
+		t.Fatalf("Get(10, 1) = %v; want banner 2", content)
+	}
+	c.Remove(1)
+	if content, _, _ := c.Get(10, 1); content["title"] != "b" {
+		t.Fatal("Remove(1) removed the pair that now belongs to banner 2")
+	}
+}
+
+func TestCacheExpiration(t *testing.T) {
+	c := newMemoryCache(-time.Second, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get returned expired item %v", content)
+	}
+	c.clearItems(c.expiredKeys())
+	if len(c.Items) != 0 || len(c.banners) != 0 || c.lru.Len() != 0 {
+		t.Fatalf("expired item was not removed: %d items, %d banners", len(c.Items), len(c.banners))
+	}
+	if stats := c.Stats(); stats.Expired != 1 || stats.Bytes != 0 {
+		t.Fatalf("Stats() = %+v; want 1 expired item, 0 bytes", stats)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	c := newMemoryCache(-time.Second, time.Hour)
+	c.hardExpiration = time.Minute
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	if content, _, stale := c.Get(10, 1); content["title"] != "a" || !stale {
+		t.Fatalf("Get(10, 1) = %v, stale %v; want stale banner 1", content, stale)
+	}
+	if keys := c.expiredKeys(); len(keys) != 0 {
+		t.Fatalf("expiredKeys() = %v; stale item must live until hard expiration", keys)
+	}
+
+	c.hardExpiration = -time.Second
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get returned item past hard expiration %v", content)
+	}
+}
+
+func TestCacheGetLastKnown(t *testing.T) {
+	c := newMemoryCache(-time.Second, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, IsActive: true, Content: models.JSONMap{"title": "a"}})
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get returned expired item %v", content)
+	}
+	if content, active := c.GetLastKnown(10, 1); content["title"] != "a" || !active {
+		t.Fatalf("GetLastKnown(10, 1) = %v, %v; want expired banner 1", content, active)
+	}
+	if content, _ := c.GetLastKnown(10, 2); content != nil {
+		t.Fatalf("GetLastKnown(10, 2) = %v; want nil", content)
+	}
+}
+
+func TestCacheAddOneIfGeneration(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+
+	// данные прочитаны из базы до изменения баннера и его инвалидации
+	generation := c.Generation()
+	c.Remove(1)
+	if c.AddOneIfGeneration(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}}, generation) {
+		t.Fatal("AddOneIfGeneration added stale item after Remove")
+	}
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get(10, 1) = %v; want nil", content)
+	}
+
+	if !c.AddOneIfGeneration(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "b"}}, c.Generation()) {
+		t.Fatal("AddOneIfGeneration rejected fresh item")
+	}
+	if content, _, _ := c.Get(10, 1); content["title"] != "b" {
+		t.Fatalf("Get(10, 1) = %v; want banner 1 with new content", content)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	c.maxEntries = 2
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	c.AddOne(Item{BannerID: 2, FeatureID: 10, TagIDs: []int32{2}, Content: models.JSONMap{"title": "b"}})
+	// баннер 1 запрошен позже баннера 2, поэтому вытесняется баннер 2
+	c.Get(10, 1)
+	c.AddOne(Item{BannerID: 3, FeatureID: 10, TagIDs: []int32{3}, Content: models.JSONMap{"title": "c"}})
+
+	if content, _, _ := c.Get(10, 2); content != nil {
+		t.Fatalf("Get(10, 2) = %v; want evicted", content)
+	}
+	if content, _, _ := c.Get(10, 1); content == nil {
+		t.Fatal("recently used banner 1 was evicted")
+	}
+	if stats := c.Stats(); stats.Entries != 2 || stats.Evictions != 1 {
+		t.Fatalf("Stats() = %+v; want 2 entries, 1 eviction", stats)
+	}
+	if _, ok := c.banners[2]; ok {
+		t.Fatal("reverse index still contains evicted banner 2")
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1, 2}, Content: models.JSONMap{"title": "a"}})
+	generation := c.Generation()
+	c.Clear()
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get(10, 1) after Clear = %v; want nil", content)
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bytes != 0 || c.lru.Len() != 0 {
+		t.Fatalf("Stats() after Clear = %+v; want empty cache", stats)
+	}
+	if c.AddOneIfGeneration(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}}, generation) {
+		t.Fatal("AddOneIfGeneration added stale item after Clear")
+	}
+}
+
+func TestCacheHot(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	c.AddOne(Item{BannerID: 2, FeatureID: 10, TagIDs: []int32{2}, Content: models.JSONMap{"title": "b"}})
+	c.AddOne(Item{BannerID: 3, FeatureID: 10, TagIDs: []int32{3}, Content: models.JSONMap{"title": "c"}})
+	c.Get(10, 1)
+
+	want := []Key{{Feature: 10, Tag: 1}, {Feature: 10, Tag: 3}}
+	if hot := c.Hot(2); !slices.Equal(hot, want) {
+		t.Fatalf("Hot(2) = %v; want %v", hot, want)
+	}
+	if hot := c.Hot(0); len(hot) != 3 {
+		t.Fatalf("Hot(0) returned %d pairs; want 3", len(hot))
+	}
+}
+
+func TestCacheByteBudget(t *testing.T) {
+	c := newMemoryCache(time.Minute, time.Hour)
+	content := models.JSONMap{"title": strings.Repeat("a", 100)}
+	size := contentSize(content)
+	c.maxBytes = 3 * size
+	for i := int32(1); i <= 5; i++ {
+		c.AddOne(Item{BannerID: i, FeatureID: 10, TagIDs: []int32{i}, Content: content})
+	}
+
+	stats := c.Stats()
+	if stats.Bytes > c.maxBytes || stats.Entries != 3 || stats.Evictions != 2 {
+		t.Fatalf("Stats() = %+v; want 3 entries within %d bytes, 2 evictions", stats, c.maxBytes)
+	}
+	c.Remove(5)
+	if stats := c.Stats(); stats.Bytes != 2*size {
+		t.Fatalf("Bytes after Remove = %d; want %d", stats.Bytes, 2*size)
+	}
+}
+
+// fillCache заполняет кэш benchEntries баннерами по 10 тэгов на фичу
+func fillCache(c *MemoryCache) {
+	for i := 0; i < benchEntries; i++ {
+		c.AddOne(Item{
+			BannerID:  int32(i + 1),
+			FeatureID: int32(i / 10),
+			TagIDs:    []int32{int32(i % 10)},
+			IsActive:  true,
+			Content:   models.JSONMap{"title": "banner"},
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	c := newMemoryCache(time.Hour, time.Hour)
+	fillCache(c)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := i % benchEntries
+		if content, _, _ := c.Get(int32(n/10), int32(n%10)); content == nil {
+			b.Fatal("cache miss")
+		}
+	}
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	c := newMemoryCache(time.Hour, time.Hour)
+	fillCache(c)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			n := i % benchEntries
+			c.Get(int32(n/10), int32(n%10))
+			i++
+		}
+	})
+}
+
+// BenchmarkGetParallelBounded - параллельные запросы к кэшу с ограничением размера, в который изредка
+// добавляются баннеры: чтения отмечают элементы lru, а вытеснение идет под блокировкой на запись
+func BenchmarkGetParallelBounded(b *testing.B) {
+	c := newMemoryCache(time.Hour, time.Hour)
+	c.maxEntries = benchEntries
+	fillCache(c)
+	var added atomic.Int32
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			n := i % benchEntries
+			if i%100 == 0 {
+				id := added.Add(1)
+				c.AddOne(Item{BannerID: benchEntries + id, FeatureID: -id, TagIDs: []int32{1}, Content: models.JSONMap{"title": "banner"}})
+			} else {
+				c.Get(int32(n/10), int32(n%10))
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkGetFullScan воспроизводит прежний поиск перебором всех элементов кэша для сравнения с BenchmarkGet
+func BenchmarkGetFullScan(b *testing.B) {
+	items := make(map[string]Item, benchEntries)
+	for i := 0; i < benchEntries; i++ {
+		key := strconv.Itoa(i+1) + "_" + strconv.Itoa(i/10)
+		items[key] = Item{TagIDs: []int32{int32(i % 10)}, Content: models.JSONMap{"title": "banner"}}
+	}
+	get := func(feature, tag int32) models.JSONMap {
+		for key, value := range items {
+			parts := strings.Split(key, "_")
+			if f, _ := strconv.Atoi(parts[1]); f == int(feature) && slices.Contains(value.TagIDs, tag) {
+				return value.Content
+			}
+		}
+		return nil
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := (i * 7919) % benchEntries
+		if get(int32(n/10), int32(n%10)) == nil {
+			b.Fatal("cache miss")
+		}
+	}
+}
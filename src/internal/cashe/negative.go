@@ -0,0 +1,134 @@
+package cashe
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultNegativeExpiration = 10 * time.Second
+
+// NegativeCache - кэш пар фича-тэг, для которых баннер не найден. Хранится в памяти процесса
+// с коротким временем жизни CACHE_NEGATIVE_EXPIRATION (0 отключает кэш)
+type NegativeCache struct {
+	sync.RWMutex
+	expiration time.Duration
+	items      map[Key]time.Time
+	// generation увеличивается при каждой инвалидации, см. AddIfGeneration
+	generation    uint64
+	hits          atomic.Int64
+	invalidations atomic.Int64
+}
+
+// NegativeStats - статистика кэша ненайденных пар
+type NegativeStats struct {
+	Hits          int64
+	Invalidations int64
+	Entries       int64
+}
+
+func NewNegativeCache() *NegativeCache {
+	exp := defaultNegativeExpiration
+	if value := os.Getenv("CACHE_NEGATIVE_EXPIRATION"); value != "" {
+		var err error
+		exp, err = time.ParseDuration(value)
+		if err != nil {
+			panic("Can't parse CACHE_NEGATIVE_EXPIRATION: " + err.Error())
+		}
+	}
+	return newNegativeCache(exp)
+}
+
+func newNegativeCache(exp time.Duration) *NegativeCache {
+	cache := NegativeCache{
+		expiration: exp,
+		items:      make(map[Key]time.Time),
+	}
+	if exp > 0 {
+		go cache.gC()
+	}
+	return &cache
+}
+
+// Has сообщает, что баннера для пары фича-тэг недавно не было в базе
+func (c *NegativeCache) Has(feature, tag int32) bool {
+	c.RLock()
+	expiration, ok := c.items[Key{Feature: feature, Tag: tag}]
+	c.RUnlock()
+	if !ok || expiration.Before(time.Now()) {
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// Generation возвращает текущее значение счетчика инвалидаций
+func (c *NegativeCache) Generation() uint64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.generation
+}
+
+// AddIfGeneration запоминает, что баннера для пары нет, только если после получения generation кэш не инвалидировался.
+// Так баннер, созданный во время чтения из базы, не будет скрыт записью об его отсутствии
+func (c *NegativeCache) AddIfGeneration(feature, tag int32, generation uint64) bool {
+	if c.expiration <= 0 {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.generation != generation {
+		return false
+	}
+	c.items[Key{Feature: feature, Tag: tag}] = time.Now().Add(c.expiration)
+	return true
+}
+
+// Invalidate удаляет записи для пар feature-tags, под которыми появился баннер
+func (c *NegativeCache) Invalidate(feature int32, tags []int32) {
+	c.Lock()
+	defer c.Unlock()
+	c.generation++
+	for _, tag := range tags {
+		key := Key{Feature: feature, Tag: tag}
+		if _, ok := c.items[key]; ok {
+			delete(c.items, key)
+			c.invalidations.Add(1)
+		}
+	}
+}
+
+// Clear удаляет все записи, когда неизвестно, под какими парами появился баннер
+func (c *NegativeCache) Clear() {
+	c.Lock()
+	defer c.Unlock()
+	c.generation++
+	c.invalidations.Add(int64(len(c.items)))
+	c.items = make(map[Key]time.Time)
+}
+
+func (c *NegativeCache) Stats() NegativeStats {
+	c.RLock()
+	defer c.RUnlock()
+	return NegativeStats{
+		Hits:          c.hits.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       int64(len(c.items)),
+	}
+}
+
+// gC удаляет устаревшие записи раз в CACHE_NEGATIVE_EXPIRATION
+func (c *NegativeCache) gC() {
+	for {
+		<-time.After(c.expiration)
+		now := time.Now()
+		c.Lock()
+		for key, expiration := range c.items {
+			if now.After(expiration) {
+				delete(c.items, key)
+			}
+		}
+		c.Unlock()
+	}
+}
@@ -0,0 +1,54 @@
+package cashe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNegativeCacheInvalidate(t *testing.T) {
+	c := newNegativeCache(time.Minute)
+	c.AddIfGeneration(10, 1, c.Generation())
+	c.AddIfGeneration(10, 2, c.Generation())
+
+	if !c.Has(10, 1) || !c.Has(10, 2) {
+		t.Fatal("Has returned false for added pairs")
+	}
+	c.Invalidate(10, []int32{1, 3})
+	if c.Has(10, 1) {
+		t.Fatal("Has(10, 1) after Invalidate = true")
+	}
+	if !c.Has(10, 2) {
+		t.Fatal("Invalidate removed pair (10, 2)")
+	}
+	if stats := c.Stats(); stats.Hits != 3 || stats.Invalidations != 1 || stats.Entries != 1 {
+		t.Fatalf("Stats() = %+v; want 3 hits, 1 invalidation, 1 entry", stats)
+	}
+}
+
+func TestNegativeCacheAddIfGeneration(t *testing.T) {
+	c := newNegativeCache(time.Minute)
+	// баннер создан, пока пара читалась из базы
+	generation := c.Generation()
+	c.Invalidate(10, []int32{1})
+	if c.AddIfGeneration(10, 1, generation) {
+		t.Fatal("AddIfGeneration added pair after Invalidate")
+	}
+	c.Clear()
+	if c.Has(10, 1) {
+		t.Fatal("Has(10, 1) = true")
+	}
+}
+
+func TestNegativeCacheExpiration(t *testing.T) {
+	c := newNegativeCache(time.Millisecond)
+	c.AddIfGeneration(10, 1, c.Generation())
+	time.Sleep(5 * time.Millisecond)
+	if c.Has(10, 1) {
+		t.Fatal("Has returned expired pair")
+	}
+
+	disabled := newNegativeCache(0)
+	if disabled.AddIfGeneration(10, 1, disabled.Generation()) || disabled.Has(10, 1) {
+		t.Fatal("negative cache with zero expiration is not disabled")
+	}
+}
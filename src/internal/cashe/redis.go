@@ -0,0 +1,181 @@
+package cashe
+
+import (
+	"banner/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisPrefix        = "banner:cache:"
+	redisGenerationKey = redisPrefix + "generation"
+	redisTimeout       = time.Second
+)
+
+// addScript кладет баннер под каждой парой фича-тэг (KEYS[3:]) и запоминает пары в множестве баннера (KEYS[2]).
+// Если ARGV[1] не пустой, элемент добавляется, только если счетчик инвалидаций (KEYS[1]) равен ARGV[1]
+var addScript = redis.NewScript(`
+if ARGV[1] ~= '' and tonumber(redis.call('GET', KEYS[1]) or '0') ~= tonumber(ARGV[1]) then
+	return 0
+end
+for i = 3, #KEYS do
+	redis.call('SET', KEYS[i], ARGV[2], 'PX', ARGV[3])
+	redis.call('SADD', KEYS[2], KEYS[i])
+end
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return 1
+`)
+
+// removeScript увеличивает счетчик инвалидаций (KEYS[1]) и удаляет пары из множества баннера (KEYS[2]),
+// которые все еще принадлежат ему (пара могла перейти к другому баннеру)
+var removeScript = redis.NewScript(`
+redis.call('INCR', KEYS[1])
+local keys = redis.call('SMEMBERS', KEYS[2])
+for _, key in ipairs(keys) do
+	local value = redis.call('GET', key)
+	if value and cjson.decode(value)['banner_id'] == tonumber(ARGV[1]) then
+		redis.call('DEL', key)
+	end
+end
+redis.call('DEL', KEYS[2])
+return #keys
+`)
+
+// RedisCache - кэш в Redis, общий для всех реплик сервиса. Элементы хранятся в JSON
+// и удаляются самим Redis по истечении CACHE_HARD_EXPIRATION (по умолчанию равного CACHE_EXPIRATION).
+// Ошибки Redis не прерывают запрос: элемент считается отсутствующим, и данные читаются из базы
+type RedisCache struct {
+	client            *redis.Client
+	defaultExpiration time.Duration
+	hardExpiration    time.Duration
+}
+
+func NewRedisCache(client *redis.Client, exp, hardExp time.Duration) (*RedisCache, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, errors.New("can't connect to redis: " + err.Error())
+	}
+	return &RedisCache{
+		client:            client,
+		defaultExpiration: exp,
+		hardExpiration:    max(exp, hardExp),
+	}, nil
+}
+
+func (c *RedisCache) AddOne(banner Item) {
+	c.add(banner, "")
+}
+
+func (c *RedisCache) Generation() uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	generation, err := c.client.Get(ctx, redisGenerationKey).Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Println("can't get cache generation: " + err.Error())
+	}
+	return generation
+}
+
+func (c *RedisCache) AddOneIfGeneration(banner Item, generation uint64) bool {
+	return c.add(banner, strconv.FormatUint(generation, 10))
+}
+
+func (c *RedisCache) add(banner Item, generation string) bool {
+	// Элемент с неположительным временем жизни сразу считается устаревшим, как и в MemoryCache
+	if c.hardExpiration <= 0 || len(banner.TagIDs) == 0 {
+		return false
+	}
+	banner.Expiration = time.Now().Add(c.defaultExpiration)
+	value, err := json.Marshal(banner)
+	if err != nil {
+		log.Println("can't marshal cache item: " + err.Error())
+		return false
+	}
+	keys := make([]string, 0, len(banner.TagIDs)+2)
+	keys = append(keys, redisGenerationKey, bannerKey(banner.BannerID))
+	for _, tag := range banner.TagIDs {
+		keys = append(keys, pairKey(banner.FeatureID, tag))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	added, err := addScript.Run(ctx, c.client, keys, generation, value, c.hardExpiration.Milliseconds()).Int()
+	if err != nil {
+		log.Println("can't add item to cache: " + err.Error())
+		return false
+	}
+	return added == 1
+}
+
+func (c *RedisCache) Get(feature, tag int32) (models.JSONMap, bool, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	value, err := c.client.Get(ctx, pairKey(feature, tag)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Println("can't get item from cache: " + err.Error())
+		}
+		return nil, false, false
+	}
+	var item Item
+	if err := json.Unmarshal(value, &item); err != nil {
+		log.Println("can't unmarshal cache item: " + err.Error())
+		return nil, false, false
+	}
+	now := time.Now()
+	return item.Content, item.IsActive && models.InWindow(item.StartsAt, item.EndsAt, now), item.Expiration.Before(now)
+}
+
+// GetLastKnown совпадает с Get: Redis сам удаляет элементы по истечении CACHE_HARD_EXPIRATION
+func (c *RedisCache) GetLastKnown(feature, tag int32) (models.JSONMap, bool) {
+	content, userAccess, _ := c.Get(feature, tag)
+	return content, userAccess
+}
+
+func (c *RedisCache) Remove(id int32) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	err := removeScript.Run(ctx, c.client, []string{redisGenerationKey, bannerKey(id)}, id).Err()
+	if err != nil {
+		log.Println("can't remove banner " + strconv.Itoa(int(id)) + " from cache: " + err.Error())
+	}
+}
+
+// Clear только увеличивает счетчик инвалидаций: кэш в Redis общий, и его элементы
+// удаляет экземпляр сервиса, который изменил баннер
+func (c *RedisCache) Clear() {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := c.client.Incr(ctx, redisGenerationKey).Err(); err != nil {
+		log.Println("can't clear cache: " + err.Error())
+	}
+}
+
+// Hot ничего не возвращает: кэш в Redis переживает перезапуск сервиса, и прогревать его не нужно
+func (c *RedisCache) Hot(n int) []Key {
+	return nil
+}
+
+// Stats возвращает пустую статистику: размер кэша и вытеснение в Redis
+// определяются настройками самого Redis (maxmemory, maxmemory-policy)
+func (c *RedisCache) Stats() Stats {
+	return Stats{}
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+func pairKey(feature, tag int32) string {
+	return redisPrefix + "pair:" + strconv.Itoa(int(feature)) + ":" + strconv.Itoa(int(tag))
+}
+
+func bannerKey(id int32) string {
+	return redisPrefix + "banner:" + strconv.Itoa(int(id))
+}
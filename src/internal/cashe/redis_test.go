@@ -0,0 +1,142 @@
+package cashe
+
+import (
+	"banner/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisCache запускает Redis внутри процесса и возвращает кэш поверх него
+func newTestRedisCache(t *testing.T, exp, hardExp time.Duration) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	c, err := NewRedisCache(redis.NewClient(&redis.Options{Addr: server.Addr()}), exp, hardExp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, server
+}
+
+func TestRedisCacheGetAndRemove(t *testing.T) {
+	c, _ := newTestRedisCache(t, time.Minute, 0)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1, 2}, IsActive: true, Content: models.JSONMap{"title": "a"}})
+	c.AddOne(Item{BannerID: 2, FeatureID: 10, TagIDs: []int32{3}, Content: models.JSONMap{"title": "b"}})
+
+	if content, active, _ := c.Get(10, 2); content["title"] != "a" || !active {
+		t.Fatalf("Get(10, 2) = %v, %v; want banner 1", content, active)
+	}
+	if content, active, _ := c.Get(10, 3); content["title"] != "b" || active {
+		t.Fatalf("Get(10, 3) = %v, %v; want inactive banner 2", content, active)
+	}
+
+	c.Remove(1)
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get(10, 1) after Remove(1) = %v; want nil", content)
+	}
+	if content, _, _ := c.Get(10, 3); content == nil {
+		t.Fatal("Remove(1) removed banner 2")
+	}
+}
+
+func TestRedisCacheAddOneReplacesPair(t *testing.T) {
+	c, _ := newTestRedisCache(t, time.Minute, 0)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	c.AddOne(Item{BannerID: 2, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "b"}})
+
+	if content, _, _ := c.Get(10, 1); content["title"] != "b" {
+		t.Fatalf("Get(10, 1) = %v; want banner 2", content)
+	}
+	c.Remove(1)
+	if content, _, _ := c.Get(10, 1); content["title"] != "b" {
+		t.Fatal("Remove(1) removed the pair that now belongs to banner 2")
+	}
+}
+
+func TestRedisCacheExpiration(t *testing.T) {
+	c, server := newTestRedisCache(t, time.Minute, 0)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	if ttl := server.TTL(pairKey(10, 1)); ttl != time.Minute {
+		t.Fatalf("TTL = %v; want CACHE_EXPIRATION %v", ttl, time.Minute)
+	}
+	server.FastForward(time.Minute)
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get returned expired item %v", content)
+	}
+
+	expired, _ := newTestRedisCache(t, -time.Second, 0)
+	expired.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	if content, _, _ := expired.Get(10, 1); content != nil {
+		t.Fatalf("Get returned item with negative expiration %v", content)
+	}
+}
+
+func TestRedisCacheStaleWhileRevalidate(t *testing.T) {
+	c, server := newTestRedisCache(t, time.Minute, time.Hour)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+	if ttl := server.TTL(pairKey(10, 1)); ttl != time.Hour {
+		t.Fatalf("TTL = %v; want CACHE_HARD_EXPIRATION %v", ttl, time.Hour)
+	}
+	if _, _, stale := c.Get(10, 1); stale {
+		t.Fatal("fresh item reported as stale")
+	}
+	// miniredis сдвигает только TTL ключей, поэтому устаревание элемента проверяем по сохраненному времени
+	c.defaultExpiration = -time.Second
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "b"}})
+	if content, _, stale := c.Get(10, 1); content["title"] != "b" || !stale {
+		t.Fatalf("Get(10, 1) = %v, stale %v; want stale banner 1", content, stale)
+	}
+	server.FastForward(time.Hour)
+	if content, _, _ := c.Get(10, 1); content != nil {
+		t.Fatalf("Get returned item past hard expiration %v", content)
+	}
+}
+
+func TestRedisCacheAddOneIfGeneration(t *testing.T) {
+	c, _ := newTestRedisCache(t, time.Minute, 0)
+	c.AddOne(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}})
+
+	generation := c.Generation()
+	c.Remove(1)
+	if c.AddOneIfGeneration(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "a"}}, generation) {
+		t.Fatal("AddOneIfGeneration added stale item after Remove")
+	}
+	if !c.AddOneIfGeneration(Item{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, Content: models.JSONMap{"title": "b"}}, c.Generation()) {
+		t.Fatal("AddOneIfGeneration rejected fresh item")
+	}
+	if content, _, _ := c.Get(10, 1); content["title"] != "b" {
+		t.Fatalf("Get(10, 1) = %v; want banner 1 with new content", content)
+	}
+}
+
+// Содержимое и окно показа после Redis должны совпадать с тем, что отдает кэш в памяти
+func TestRedisCacheMatchesMemoryCache(t *testing.T) {
+	var content models.JSONMap
+	if err := content.Scan([]byte(`{"title": "a", "width": 300, "ratio": 1.5, "tags": [1, "x", null], "nested": {"ok": true}}`)); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	items := []Item{
+		{BannerID: 1, FeatureID: 10, TagIDs: []int32{1}, IsActive: true, Content: content},
+		{BannerID: 2, FeatureID: 10, TagIDs: []int32{2}, IsActive: true, StartsAt: &future, Content: content},
+		{BannerID: 3, FeatureID: 10, TagIDs: []int32{3}, IsActive: true, StartsAt: &past, EndsAt: &future, Content: content},
+	}
+	memory := newMemoryCache(time.Minute, time.Hour)
+	c, _ := newTestRedisCache(t, time.Minute, 0)
+	for _, item := range items {
+		memory.AddOne(item)
+		c.AddOne(item)
+	}
+	for _, item := range items {
+		wantContent, wantActive, _ := memory.Get(item.FeatureID, item.TagIDs[0])
+		gotContent, gotActive, _ := c.Get(item.FeatureID, item.TagIDs[0])
+		if !reflect.DeepEqual(gotContent, wantContent) || gotActive != wantActive {
+			t.Fatalf("banner %d: redis = %v, %v; memory = %v, %v", item.BannerID, gotContent, gotActive, wantContent, wantActive)
+		}
+	}
+}
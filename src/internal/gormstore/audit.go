@@ -0,0 +1,103 @@
+package gormstore
+
+import (
+	"banner/internal/repository"
+	"banner/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditRecord - запись журнала изменений баннеров. Журнал только дополняется:
+// изменение и удаление записей запрещено триггером (миграция 0003_create_audit_log)
+type AuditRecord struct {
+	Id        int64          `gorm:"primary_key;auto_increment"`
+	BannerId  int32          `gorm:"index;not null"`
+	Actor     string         `gorm:"type:varchar(255);index"`
+	Action    string         `gorm:"type:varchar(32);not null"`
+	RequestId string         `gorm:"type:varchar(64)"`
+	Before    models.JSONMap `gorm:"type:json"`
+	After     models.JSONMap `gorm:"type:json"`
+	Diff      models.JSONMap `gorm:"type:json"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index"`
+}
+
+func (AuditRecord) TableName() string {
+	return "audit_log"
+}
+
+// writeAudit записывает изменение баннера в журнал в рамках транзакции tx и уведомляет о нем
+// другие экземпляры сервиса (см. Dialect.Notify). before и after - состояния баннера до и после изменения
+// (nil, если баннера не было или он удален)
+func (s *Store) writeAudit(tx *gorm.DB, id int32, action string, info models.AuditInfo, before, after *Revision) error {
+	record := AuditRecord{
+		BannerId:  id,
+		Actor:     info.Actor,
+		Action:    action,
+		RequestId: info.RequestId,
+		Before:    bannerState(before),
+		After:     bannerState(after),
+	}
+	record.Diff = repository.DiffStates(record.Before, record.After)
+	if err := tx.Create(&record).Error; err != nil {
+		return errors.New("can't write audit log: " + err.Error())
+	}
+	return s.dialect.Notify(tx, id, before, after)
+}
+
+// Audit возвращает записи журнала изменений, начиная с последних
+func (s *Store) Audit(filter models.AuditFilter) ([]models.AuditGet200ResponseInner, error) {
+	query := s.Db.Model(&AuditRecord{})
+	if filter.BannerId > 0 {
+		query = query.Where("banner_id = ?", filter.BannerId)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = repository.DefaultAuditLimit
+	}
+	var records []AuditRecord
+	err := query.Order("created_at DESC, id DESC").Limit(int(limit)).Offset(int(filter.Offset)).Find(&records).Error
+	if err != nil {
+		return nil, errors.New("can't read audit log: " + err.Error())
+	}
+	res := make([]models.AuditGet200ResponseInner, 0, len(records))
+	for _, r := range records {
+		res = append(res, models.AuditGet200ResponseInner{
+			Id:        r.Id,
+			BannerId:  r.BannerId,
+			Actor:     r.Actor,
+			Action:    r.Action,
+			RequestId: r.RequestId,
+			Before:    r.Before,
+			After:     r.After,
+			Diff:      r.Diff,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// bannerState переводит состояние баннера в JSON-объект для журнала
+func bannerState(rev *Revision) models.JSONMap {
+	if rev == nil {
+		return nil
+	}
+	return repository.BannerState(&models.BannerIdVersionsGet200ResponseInner{
+		FeatureId: rev.Feature,
+		TagIds:    rev.TagIds,
+		Content:   rev.Content,
+		IsActive:  rev.IsActive,
+		StartsAt:  rev.StartsAt,
+		EndsAt:    rev.EndsAt,
+	})
+}
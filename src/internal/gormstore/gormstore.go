@@ -0,0 +1,1096 @@
+// Package gormstore содержит модели gorm и запросы, общие для хранилищ баннеров на SQL-базах (Postgres и SQLite).
+// Различия баз описываются интерфейсом Dialect, который реализует каждое хранилище
+package gormstore
+
+import (
+	"banner/internal/repository"
+	"banner/models"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"slices"
+	"time"
+)
+
+// Dialect - особенности базы данных, поверх которой работает Store
+type Dialect interface {
+	// Read выполняет чтение fc, допускающее отставание от последней версии (например, на реплике)
+	Read(fc func(db *gorm.DB) error) error
+	// Notify вызывается в транзакции tx каждого изменения баннера id, before и after - состояния до и после изменения
+	Notify(tx *gorm.DB, id int32, before, after *Revision) error
+	// WhereContent добавляет к запросу условия filter на содержимое баннеров. Если база не умеет проверять их
+	// в запросе, возвращает запрос без изменений и false
+	WhereContent(query *gorm.DB, filter models.BannerFilter) (*gorm.DB, bool)
+}
+
+// Store - хранилище баннеров поверх gorm. Реализует repository.Repository, кроме Listen
+type Store struct {
+	Db             *gorm.DB
+	dialect        Dialect
+	revisionsLimit int
+	trashRetention time.Duration
+	purgeInterval  time.Duration
+	stop           chan struct{}
+}
+
+// New создает хранилище поверх открытой базы db со схемой из моделей пакета
+func New(db *gorm.DB, dialect Dialect, cfg repository.Config) *Store {
+	return &Store{
+		Db:             db,
+		dialect:        dialect,
+		revisionsLimit: cfg.RevisionsLimit,
+		trashRetention: cfg.TrashRetention,
+		purgeInterval:  cfg.PurgeInterval,
+		stop:           make(chan struct{}),
+	}
+}
+
+// Ping проверяет соединение с базой данных
+func (s *Store) Ping() error {
+	return Ping(s.Db)
+}
+
+// Stop останавливает удаление баннеров из корзины и закрывает соединение с базой
+func (s *Store) Stop() error {
+	close(s.stop)
+	val, err := s.Db.DB()
+	if err != nil {
+		return errors.New("failed to get database; error: " + err.Error())
+	}
+	if err := val.Close(); err != nil {
+		return errors.New("failed to close database connection; error: " + err.Error())
+	}
+	return nil
+}
+
+// Ping проверяет соединение с базой db
+func Ping(db *gorm.DB) error {
+	val, err := db.DB()
+	if err != nil {
+		return errors.New("failed to get database; error: " + err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return val.PingContext(ctx)
+}
+
+func (s *Store) Insert(record *models.InsertData) (int32, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if tx.Error != nil {
+		return 0, errors.New("can't start transaction; error: " + tx.Error.Error())
+	}
+
+	id, err := s.insert(tx, record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, errors.New("can't commit transaction: " + err.Error())
+	}
+
+	return id, nil
+}
+
+// InsertBatch создает баннеры records в одной транзакции
+func (s *Store) InsertBatch(records []*models.InsertData) ([]int32, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if tx.Error != nil {
+		return nil, errors.New("can't start transaction; error: " + tx.Error.Error())
+	}
+
+	ids := make([]int32, 0, len(records))
+	for i, record := range records {
+		id, err := s.insert(tx, record)
+		if err != nil {
+			tx.Rollback()
+			return nil, &repository.BatchError{Index: i, Err: err}
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("can't commit transaction: " + err.Error())
+	}
+
+	return ids, nil
+}
+
+// insert создает баннер record в транзакции tx вместе с первой версией и записью в журнале
+func (s *Store) insert(tx *gorm.DB, record *models.InsertData) (int32, error) {
+	d := Data{
+		Content:  record.Content,
+		IsActive: record.IsActive,
+		StartsAt: record.StartsAt,
+		EndsAt:   record.EndsAt,
+	}
+	if err := tx.Create(&d).Error; err != nil {
+		return 0, errors.New("can't insert data: " + err.Error())
+	}
+	banners := make([]Banner, 0, len(record.TagIds))
+	for _, i := range record.TagIds {
+		banners = append(banners, Banner{DataId: d.Id, Feature: record.Feature, Tag: i})
+	}
+	if err := tx.Create(&banners).Error; err != nil {
+		return 0, pairsError("can't insert banner", err)
+	}
+	after, err := s.saveRevision(tx, d.Id, record.Actor)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.writeAudit(tx, d.Id, repository.ActionCreate, record.AuditInfo, nil, &after); err != nil {
+		return 0, err
+	}
+	return d.Id, nil
+}
+
+// Get возвращает баннер с фичей feature и тэгом tag вместе с флагом активности и окном показа.
+// Если не latest, баннер читается через Dialect.Read (например, с реплики)
+func (s *Store) Get(feature, tag int32, latest bool) (result models.BannerGet200ResponseInner, found bool, err error) {
+	if latest {
+		return s.get(s.Db, feature, tag)
+	}
+	err = s.dialect.Read(func(db *gorm.DB) error {
+		result, found, err = s.get(db, feature, tag)
+		return err
+	})
+	return
+}
+
+func (s *Store) get(db *gorm.DB, feature, tag int32) (result models.BannerGet200ResponseInner, found bool, err error) {
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	found = false
+	err = nil
+
+	if tx.Error != nil {
+		err = fmt.Errorf("can't start transaction: %w", tx.Error)
+		return
+	}
+
+	id, errId := s.findId(feature, tag, tx)
+	if errId != nil {
+		tx.Rollback()
+		// ошибку базы возвращаем, чтобы чтение с недоступной реплики повторилось на основной базе
+		if !errors.Is(errId, gorm.ErrRecordNotFound) {
+			err = errId
+		}
+		return
+	}
+
+	var d Data
+	if err = tx.Where("id = ?", id).First(&d).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		err = fmt.Errorf("failed to find banner: %w", err)
+		return
+	}
+	found = true
+	if err = tx.Commit().Error; err != nil {
+		err = fmt.Errorf("failed to commit transaction: %w", err)
+		return
+	}
+
+	result = models.BannerGet200ResponseInner{
+		BannerId:  d.Id,
+		FeatureId: feature,
+		Content:   d.Content,
+		IsActive:  d.IsActive,
+		StartsAt:  d.StartsAt,
+		EndsAt:    d.EndsAt,
+		Version:   d.Version,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+	return
+}
+
+// pairsError описывает ошибку записи пар фича-тэг. Нарушение уникальности пары возвращается как ErrConflict
+func pairsError(msg string, err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%s: %w", msg, repository.ErrConflict)
+	}
+	return errors.New(msg + ": " + err.Error())
+}
+
+func (s *Store) findId(feature, tag int32, tx *gorm.DB) (int32, error) {
+	var idToFind Banner
+	if err := tx.Model(&Banner{}).Where("feature = ? AND tag = ?", feature, tag).First(&idToFind).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("banner with feature %d and tag %d not found: %w", feature, tag, err)
+		}
+		return 0, fmt.Errorf("failed to find banner: %w", err)
+	}
+	return idToFind.DataId, nil
+}
+
+func (s *Store) Update(id int32, newValue *models.InsertData) (int32, bool, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	found, err := s.checkVersion(tx, id, newValue.ExpectedVersion)
+	if !found || err != nil {
+		tx.Rollback()
+		return 0, found, err
+	}
+	before, err := s.snapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return 0, true, err
+	}
+	if !models.ValidWindow(newValue.Window(before.StartsAt, before.EndsAt)) {
+		tx.Rollback()
+		return 0, true, repository.ErrInvalidWindow
+	}
+	if err := s.ensureRevision(tx, id); err != nil {
+		tx.Rollback()
+		return 0, true, err
+	}
+	if len(newValue.TagIds) > 0 || newValue.Feature > 0 {
+		var deletedBanners []Banner
+		if err := tx.Model(&Banner{}).Where("data_id = ?", id).Find(&deletedBanners).Error; err != nil {
+			tx.Rollback()
+			return 0, true, errors.New("can't find banner tags: " + err.Error())
+		}
+		if err := tx.Model(&Banner{}).Where("data_id = ?", id).Delete(&deletedBanners).Error; err != nil {
+			tx.Rollback()
+			return 0, true, errors.New("can't update banner: " + err.Error())
+		}
+		feature := newValue.Feature
+		if feature == 0 && len(deletedBanners) > 0 {
+			feature = deletedBanners[0].Feature
+		}
+		for i := range deletedBanners {
+			deletedBanners[i].Feature = feature
+		}
+
+		numOfTags := len(newValue.TagIds)
+		if numOfTags > 0 {
+			if feature == 0 {
+				// у баннера нет ни одного тэга, и фича для новых тэгов неизвестна
+				tx.Rollback()
+				return 0, true, errors.New("can't update banner: feature is required to add tags")
+			}
+			for i := 0; i < numOfTags && i < len(deletedBanners); i++ {
+				deletedBanners[i].Tag = newValue.TagIds[i]
+			}
+			for i := len(deletedBanners); i < numOfTags; i++ {
+				deletedBanners = append(deletedBanners, Banner{DataId: id, Feature: feature, Tag: newValue.TagIds[i]})
+			}
+		}
+		if len(deletedBanners) > 0 {
+			if err := tx.Model(&Banner{}).Create(deletedBanners).Error; err != nil {
+				tx.Rollback()
+				return 0, true, pairsError("can't update banner", err)
+			}
+		}
+
+	}
+	newData := Data{
+		Content:  newValue.Content,
+		IsActive: newValue.IsActive,
+		StartsAt: newValue.StartsAt,
+		EndsAt:   newValue.EndsAt,
+	}
+	errUpd := tx.Model(&Data{}).Where("id = ?", id).Updates(&newData)
+	if errUpd.Error != nil {
+		tx.Rollback()
+		return 0, true, errors.New("can't update banner: " + errUpd.Error.Error())
+	}
+	// Updates со структурой пропускает nil и false, поэтому сброшенные границы окна и снятый флаг активности
+	// записываются отдельно
+	zeroed := make(map[string]interface{})
+	if newValue.ClearStartsAt {
+		zeroed["starts_at"] = nil
+	}
+	if newValue.ClearEndsAt {
+		zeroed["ends_at"] = nil
+	}
+	if newValue.SetIsActive && !newValue.IsActive {
+		zeroed["is_active"] = false
+	}
+	if len(zeroed) > 0 {
+		if err := tx.Model(&Data{}).Where("id = ?", id).Updates(zeroed).Error; err != nil {
+			tx.Rollback()
+			return 0, true, errors.New("can't update banner: " + err.Error())
+		}
+	}
+	version, err := bumpVersion(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return 0, true, err
+	}
+	after, err := s.saveRevision(tx, id, newValue.Actor)
+	if err != nil {
+		tx.Rollback()
+		return 0, true, err
+	}
+	if err := s.writeAudit(tx, id, repository.ActionUpdate, newValue.AuditInfo, &before, &after); err != nil {
+		tx.Rollback()
+		return 0, true, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, true, err
+	}
+	return version, true, nil
+}
+
+// GetById возвращает баннер id вместе с его версией
+func (s *Store) GetById(id int32) (models.BannerGet200ResponseInner, bool, error) {
+	var d Data
+	if err := s.Db.Where("id = ?", id).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BannerGet200ResponseInner{}, false, nil
+		}
+		return models.BannerGet200ResponseInner{}, false, errors.New("can't find banner: " + err.Error())
+	}
+	var banners []Banner
+	if err := s.Db.Where("data_id = ?", id).Order("tag").Find(&banners).Error; err != nil {
+		return models.BannerGet200ResponseInner{}, true, errors.New("can't find banner: " + err.Error())
+	}
+	res := models.BannerGet200ResponseInner{
+		BannerId:  d.Id,
+		TagIds:    make([]int32, 0, len(banners)),
+		Content:   d.Content,
+		IsActive:  d.IsActive,
+		StartsAt:  d.StartsAt,
+		EndsAt:    d.EndsAt,
+		Version:   d.Version,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+	for _, b := range banners {
+		res.FeatureId = b.Feature
+		res.TagIds = append(res.TagIds, b.Tag)
+	}
+	return res, true, nil
+}
+
+// ActiveBanners возвращает активные баннеры, показ которых еще не закончился, начиная с последних измененных.
+// limit <= 0 - без ограничения
+func (s *Store) ActiveBanners(ctx context.Context, limit int) ([]models.BannerGet200ResponseInner, error) {
+	query := s.Db.WithContext(ctx).Where("is_active = ? AND (ends_at IS NULL OR ends_at > ?)", true, time.Now()).
+		Order("updated_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var data []Data
+	if err := query.Find(&data).Error; err != nil {
+		return nil, errors.New("can't find active banners: " + err.Error())
+	}
+	ids := make([]int32, 0, len(data))
+	for _, d := range data {
+		ids = append(ids, d.Id)
+	}
+	var banners []Banner
+	if err := s.Db.WithContext(ctx).Where("data_id IN (?)", ids).Order("tag").Find(&banners).Error; err != nil {
+		return nil, errors.New("can't find active banners: " + err.Error())
+	}
+	res := make([]models.BannerGet200ResponseInner, 0, len(data))
+	index := make(map[int32]int, len(data))
+	for _, d := range data {
+		index[d.Id] = len(res)
+		res = append(res, models.BannerGet200ResponseInner{
+			BannerId: d.Id,
+			TagIds:   make([]int32, 0),
+			Content:  d.Content,
+			IsActive: d.IsActive,
+			StartsAt: d.StartsAt,
+			EndsAt:   d.EndsAt,
+			Version:  d.Version,
+		})
+	}
+	for _, b := range banners {
+		banner := &res[index[b.DataId]]
+		banner.FeatureId = b.Feature
+		banner.TagIds = append(banner.TagIds, b.Tag)
+	}
+	return res, nil
+}
+
+// checkVersion блокирует строку баннера до конца транзакции и сравнивает ее версию с ожидаемой.
+// Нулевая ожидаемая версия не проверяется
+func (s *Store) checkVersion(tx *gorm.DB, id int32, expected int32) (bool, error) {
+	var current Data
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "version").Where("id = ?", id).Limit(1).Find(&current)
+	if res.Error != nil {
+		return false, errors.New("can't find banner: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	if expected != 0 && current.Version != expected {
+		return true, repository.ErrVersionMismatch
+	}
+	return true, nil
+}
+
+// bumpVersion увеличивает версию баннера после изменения и возвращает новую версию
+func bumpVersion(tx *gorm.DB, id int32) (int32, error) {
+	err := tx.Model(&Data{}).Where("id = ?", id).UpdateColumn("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		return 0, errors.New("can't update banner version: " + err.Error())
+	}
+	var version int32
+	if err := tx.Model(&Data{}).Where("id = ?", id).Select("version").Scan(&version).Error; err != nil {
+		return 0, errors.New("can't get banner version: " + err.Error())
+	}
+	return version, nil
+}
+
+// Versions возвращает сохраненные версии баннера, начиная с самой новой
+func (s *Store) Versions(id int32) ([]models.BannerIdVersionsGet200ResponseInner, bool, error) {
+	var count int64
+	if err := s.Db.Model(&Data{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, false, errors.New("can't find banner: " + err.Error())
+	}
+	if count == 0 {
+		return nil, false, nil
+	}
+	var revisions []Revision
+	if err := s.Db.Where("data_id = ?", id).Order("version DESC").Find(&revisions).Error; err != nil {
+		return nil, true, errors.New("can't get versions: " + err.Error())
+	}
+	res := make([]models.BannerIdVersionsGet200ResponseInner, 0, len(revisions))
+	for _, r := range revisions {
+		res = append(res, models.BannerIdVersionsGet200ResponseInner{
+			Version:   r.Version,
+			TagIds:    r.TagIds,
+			FeatureId: r.Feature,
+			Content:   r.Content,
+			IsActive:  r.IsActive,
+			StartsAt:  r.StartsAt,
+			EndsAt:    r.EndsAt,
+			Author:    r.Author,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return res, true, nil
+}
+
+// RestoreVersion делает версию version баннера id текущей. Восстановление сохраняется как новая версия
+func (s *Store) RestoreVersion(id int32, version int32, info models.AuditInfo) (bool, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if tx.Error != nil {
+		return false, errors.New("can't start transaction; error: " + tx.Error.Error())
+	}
+
+	var count int64
+	if err := tx.Model(&Data{}).Where("id = ?", id).Count(&count).Error; err != nil || count == 0 {
+		tx.Rollback()
+		if err != nil {
+			return false, errors.New("can't find banner: " + err.Error())
+		}
+		return false, nil
+	}
+	var rev Revision
+	if err := tx.Where("data_id = ? AND version = ?", id, version).First(&rev).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, errors.New("can't find version: " + err.Error())
+	}
+	before, err := s.snapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return true, err
+	}
+
+	if err := tx.Where("data_id = ?", id).Delete(&Banner{}).Error; err != nil {
+		tx.Rollback()
+		return true, errors.New("can't restore banner: " + err.Error())
+	}
+	banners := make([]Banner, 0, len(rev.TagIds))
+	for _, tag := range rev.TagIds {
+		banners = append(banners, Banner{DataId: id, Feature: rev.Feature, Tag: tag})
+	}
+	if len(banners) > 0 {
+		if err := tx.Create(&banners).Error; err != nil {
+			tx.Rollback()
+			return true, pairsError("can't restore banner", err)
+		}
+	}
+	restored := Data{
+		Content:  rev.Content,
+		IsActive: rev.IsActive,
+		StartsAt: rev.StartsAt,
+		EndsAt:   rev.EndsAt,
+	}
+	err = tx.Model(&Data{}).Where("id = ?", id).
+		Select("content", "is_active", "starts_at", "ends_at", "updated_at").
+		Updates(&restored).Error
+	if err != nil {
+		tx.Rollback()
+		return true, errors.New("can't restore banner: " + err.Error())
+	}
+	if _, err := bumpVersion(tx, id); err != nil {
+		tx.Rollback()
+		return true, err
+	}
+	after, err := s.saveRevision(tx, id, info.Actor)
+	if err != nil {
+		tx.Rollback()
+		return true, err
+	}
+	if err := s.writeAudit(tx, id, repository.ActionRestoreVersion, info, &before, &after); err != nil {
+		tx.Rollback()
+		return true, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+// snapshot собирает текущее состояние баннера в одну версию
+func (s *Store) snapshot(tx *gorm.DB, id int32) (Revision, error) {
+	var d Data
+	if err := tx.Where("id = ?", id).First(&d).Error; err != nil {
+		return Revision{}, errors.New("can't read banner: " + err.Error())
+	}
+	var banners []Banner
+	if err := tx.Where("data_id = ?", id).Order("tag").Find(&banners).Error; err != nil {
+		return Revision{}, errors.New("can't read banner: " + err.Error())
+	}
+	rev := Revision{
+		DataId:   id,
+		TagIds:   make(models.TagList, 0, len(banners)),
+		Content:  d.Content,
+		IsActive: d.IsActive,
+		StartsAt: d.StartsAt,
+		EndsAt:   d.EndsAt,
+	}
+	for _, b := range banners {
+		rev.Feature = b.Feature
+		rev.TagIds = append(rev.TagIds, b.Tag)
+	}
+	return rev, nil
+}
+
+// ensureRevision сохраняет текущее состояние баннера, если для него еще нет ни одной версии
+// (баннеры, созданные до появления истории изменений)
+func (s *Store) ensureRevision(tx *gorm.DB, id int32) error {
+	var count int64
+	if err := tx.Model(&Revision{}).Where("data_id = ?", id).Count(&count).Error; err != nil {
+		return errors.New("can't count versions: " + err.Error())
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := s.saveRevision(tx, id, "")
+	return err
+}
+
+// saveRevision сохраняет текущее состояние баннера как новую версию и удаляет версии сверх revisionsLimit
+func (s *Store) saveRevision(tx *gorm.DB, id int32, author string) (Revision, error) {
+	rev, err := s.snapshot(tx, id)
+	if err != nil {
+		return Revision{}, err
+	}
+	var last int32
+	if err := tx.Model(&Revision{}).Where("data_id = ?", id).Select("COALESCE(MAX(version), 0)").Scan(&last).Error; err != nil {
+		return Revision{}, errors.New("can't get last version: " + err.Error())
+	}
+	rev.Version = last + 1
+	rev.Author = author
+	if err := tx.Create(&rev).Error; err != nil {
+		return Revision{}, errors.New("can't save version: " + err.Error())
+	}
+	if s.revisionsLimit > 0 {
+		err := tx.Where("data_id = ? AND version <= ?", id, rev.Version-int32(s.revisionsLimit)).Delete(&Revision{}).Error
+		if err != nil {
+			return Revision{}, errors.New("can't remove old versions: " + err.Error())
+		}
+	}
+	return rev, nil
+}
+
+// Delete удаляет баннер id. Если expectedVersion не равна нулю, а версия баннера отличается от нее,
+// возвращает repository.ErrVersionMismatch
+func (s *Store) Delete(id int32, expectedVersion int32, info models.AuditInfo) (bool, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	found, errVer := s.checkVersion(tx, id, expectedVersion)
+	if !found || errVer != nil {
+		tx.Rollback()
+		return found, errVer
+	}
+	before, errSnap := s.snapshot(tx, id)
+	if errSnap != nil {
+		tx.Rollback()
+		return true, errSnap
+	}
+	// Фича и тэги удаленного баннера остаются в последней версии, по ней баннер восстанавливается из корзины
+	if errRev := s.ensureRevision(tx, id); errRev != nil {
+		tx.Rollback()
+		return true, errRev
+	}
+	err := tx.Where("data_id = ?", id).Delete(&Banner{})
+	if err.Error != nil {
+		tx.Rollback()
+		return true, errors.New("can't delete banner: " + err.Error.Error())
+	}
+	err = tx.Delete(&Data{}, id)
+	if err.Error != nil {
+		tx.Rollback()
+		return true, errors.New("can't delete data: " + err.Error.Error())
+	}
+	if errAudit := s.writeAudit(tx, id, repository.ActionDelete, info, &before, nil); errAudit != nil {
+		tx.Rollback()
+		return true, errAudit
+	}
+	if tx.Error != nil {
+		tx.Rollback()
+		return true, errors.New("something went wrong: " + tx.Error.Error())
+	}
+	return true, tx.Commit().Error
+}
+
+// Trash возвращает удаленные баннеры, которые еще не были окончательно удалены, начиная с последних
+func (s *Store) Trash(limit int32, offset int32) ([]models.BannerTrashGet200ResponseInner, error) {
+	if limit == 0 {
+		limit = -1
+	}
+	if offset == 0 {
+		offset = -1
+	}
+	var deleted []Data
+	err := s.Db.Unscoped().Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Limit(int(limit)).Offset(int(offset)).
+		Find(&deleted).Error
+	if err != nil {
+		return nil, errors.New("can't get deleted banners: " + err.Error())
+	}
+	ids := make([]int32, 0, len(deleted))
+	for _, d := range deleted {
+		ids = append(ids, d.Id)
+	}
+	var revisions []Revision
+	if err := s.Db.Where("data_id IN (?)", ids).Order("version").Find(&revisions).Error; err != nil {
+		return nil, errors.New("can't get versions: " + err.Error())
+	}
+	last := make(map[int32]Revision, len(ids))
+	for _, r := range revisions {
+		last[r.DataId] = r
+	}
+	res := make([]models.BannerTrashGet200ResponseInner, 0, len(deleted))
+	for _, d := range deleted {
+		res = append(res, models.BannerTrashGet200ResponseInner{
+			BannerId:  d.Id,
+			TagIds:    last[d.Id].TagIds,
+			FeatureId: last[d.Id].Feature,
+			Content:   d.Content,
+			IsActive:  d.IsActive,
+			StartsAt:  d.StartsAt,
+			EndsAt:    d.EndsAt,
+			CreatedAt: d.CreatedAt,
+			UpdatedAt: d.UpdatedAt,
+			DeletedAt: d.DeletedAt.Time,
+		})
+	}
+	return res, nil
+}
+
+// Restore восстанавливает удаленный баннер с фичей и тэгами из его последней версии.
+// Если какая-то из пар фича-тэг уже занята, возвращает repository.ErrConflict
+func (s *Store) Restore(id int32, info models.AuditInfo) (bool, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if tx.Error != nil {
+		return false, errors.New("can't start transaction; error: " + tx.Error.Error())
+	}
+
+	var count int64
+	if err := tx.Unscoped().Model(&Data{}).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return false, errors.New("can't find deleted banner: " + err.Error())
+	}
+	if count == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+	var rev Revision
+	if err := tx.Where("data_id = ?", id).Order("version DESC").First(&rev).Error; err != nil {
+		tx.Rollback()
+		return true, errors.New("can't find last version: " + err.Error())
+	}
+	if len(rev.TagIds) > 0 {
+		var used int64
+		err := tx.Model(&Banner{}).Where("feature = ? AND tag IN (?)", rev.Feature, []int32(rev.TagIds)).Count(&used).Error
+		if err != nil {
+			tx.Rollback()
+			return true, errors.New("can't check banners: " + err.Error())
+		}
+		if used > 0 {
+			tx.Rollback()
+			return true, repository.ErrConflict
+		}
+		banners := make([]Banner, 0, len(rev.TagIds))
+		for _, tag := range rev.TagIds {
+			banners = append(banners, Banner{DataId: id, Feature: rev.Feature, Tag: tag})
+		}
+		if err := tx.Create(&banners).Error; err != nil {
+			tx.Rollback()
+			return true, errors.New("can't restore banner: " + err.Error())
+		}
+	}
+	restored := map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	}
+	if err := tx.Unscoped().Model(&Data{}).Where("id = ?", id).Updates(restored).Error; err != nil {
+		tx.Rollback()
+		return true, errors.New("can't restore banner: " + err.Error())
+	}
+	// восстановление сохраняется как новая версия, чтобы версия баннера совпадала с номером последней версии
+	after, err := s.saveRevision(tx, id, info.Actor)
+	if err != nil {
+		tx.Rollback()
+		return true, err
+	}
+	if err := s.writeAudit(tx, id, repository.ActionRestore, info, nil, &after); err != nil {
+		tx.Rollback()
+		return true, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+// StartPurger запускает периодическое удаление баннеров из корзины. Останавливается вместе с Store
+func (s *Store) StartPurger() {
+	go s.purger()
+}
+
+// purger периодически окончательно удаляет баннеры, пролежавшие в корзине дольше trashRetention
+func (s *Store) purger() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(s.purgeInterval):
+		}
+		if _, err := s.Purge(time.Now().Add(-s.trashRetention)); err != nil {
+			log.Println("can't purge deleted banners: " + err.Error())
+		}
+	}
+}
+
+// Purge окончательно удаляет баннеры, удаленные раньше before, вместе с их версиями
+func (s *Store) Purge(before time.Time) (int64, error) {
+	tx := s.Db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if tx.Error != nil {
+		return 0, errors.New("can't start transaction; error: " + tx.Error.Error())
+	}
+
+	var ids []int32
+	if err := tx.Unscoped().Model(&Data{}).Where("deleted_at < ?", before).Pluck("id", &ids).Error; err != nil {
+		tx.Rollback()
+		return 0, errors.New("can't find deleted banners: " + err.Error())
+	}
+	if len(ids) == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+	if err := tx.Where("data_id IN (?)", ids).Delete(&Revision{}).Error; err != nil {
+		tx.Rollback()
+		return 0, errors.New("can't purge versions: " + err.Error())
+	}
+	res := tx.Unscoped().Where("id IN (?)", ids).Delete(&Data{})
+	if res.Error != nil {
+		tx.Rollback()
+		return 0, errors.New("can't purge banners: " + res.Error.Error())
+	}
+	for _, id := range ids {
+		if err := s.writeAudit(tx, id, repository.ActionPurge, models.AuditInfo{Actor: repository.SystemActor}, nil, nil); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return res.RowsAffected, tx.Commit().Error
+}
+
+// FindIds возвращает идентификаторы баннеров с фичей featureId и/или тэгом tagId (нулевой фильтр не учитывается)
+func (s *Store) FindIds(featureId int32, tagId int32) ([]int32, error) {
+	query := s.Db.Model(&Banner{})
+	if featureId > 0 {
+		query = query.Where("feature = ?", featureId)
+	}
+	if tagId > 0 {
+		query = query.Where("tag = ?", tagId)
+	}
+	var ids []int32
+	if err := query.Distinct("data_id").Order("data_id").Pluck("data_id", &ids).Error; err != nil {
+		return nil, errors.New("can't find banners: " + err.Error())
+	}
+	return ids, nil
+}
+
+// GetMany возвращает страницу баннеров, удовлетворяющих filter, и общее количество таких баннеров.
+// Баннеры читаются через Dialect.Read
+func (s *Store) GetMany(filter models.BannerFilter) (res []map[string]interface{}, total int64, err error) {
+	column, ok := sortColumns[filter.Sort.Field]
+	if !ok {
+		return nil, 0, errors.New("unknown sort field: " + filter.Sort.Field)
+	}
+	err = s.dialect.Read(func(db *gorm.DB) error {
+		res, total, err = s.getMany(db, filter, column)
+		return err
+	})
+	return
+}
+
+func (s *Store) getMany(db *gorm.DB, filter models.BannerFilter, column string) ([]map[string]interface{}, int64, error) {
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	limit, offset := int(filter.Limit), int(filter.Offset)
+	if limit == 0 {
+		limit = -1
+	}
+	if offset == 0 {
+		offset = -1
+	}
+	bannersIds := make(map[int32]string)
+	var resData []Data
+	var resBanners []Banner
+	query := tx.Model(&Data{})
+	if len(filter.FeatureIds) > 0 || len(filter.TagIds) > 0 {
+		banners := tx.Model(&Banner{}).Select("data_id")
+		if len(filter.FeatureIds) > 0 {
+			banners = banners.Where("feature IN ?", filter.FeatureIds)
+		}
+		if len(filter.TagIds) > 0 {
+			banners = banners.Where("tag IN ?", filter.TagIds)
+		}
+		query = query.Where("id IN (?)", banners)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	query = whereRange(query, "created_at", filter.CreatedFrom, filter.CreatedTo)
+	query = whereRange(query, "updated_at", filter.UpdatedFrom, filter.UpdatedTo)
+	// если база не проверяет условия на содержимое в запросе, они проверяются после выборки
+	filterContent := filter.ContentContains != nil || len(filter.ContentFields) > 0
+	query, contentInQuery := s.dialect.WhereContent(query, filter)
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if contentInQuery || !filterContent {
+		if err := query.Count(&total).Error; err != nil {
+			tx.Rollback()
+			return nil, 0, errors.New("can't count banners: " + err.Error())
+		}
+		query = whereAfter(orderBy(query, column, filter.Sort.Desc), column, filter).Limit(limit).Offset(offset)
+		if err := query.Find(&resData).Error; err != nil {
+			tx.Rollback()
+			return nil, 0, errors.New("can't find banners: " + err.Error())
+		}
+	} else {
+		if err := orderBy(query, column, filter.Sort.Desc).Find(&resData).Error; err != nil {
+			tx.Rollback()
+			return nil, 0, errors.New("can't find banners: " + err.Error())
+		}
+		resData = matchContent(resData, filter)
+		total = int64(len(resData))
+		if filter.After != nil && !filter.After.First() {
+			resData = slices.DeleteFunc(resData, func(d Data) bool {
+				return !filter.Sort.Before(filter.After.BannerKey, dataKey(d, column))
+			})
+		}
+		resData = pageData(resData, int(filter.Limit), int(filter.Offset))
+	}
+	ids := make([]int32, 0, len(resData))
+	for _, d := range resData {
+		ids = append(ids, d.Id)
+	}
+	if err := tx.Model(&Banner{}).Where("data_id IN (?)", ids).Find(&resBanners).Error; err != nil {
+		tx.Rollback()
+		return nil, 0, errors.New("can't find banners: " + err.Error())
+	}
+	res := make([]map[string]interface{}, 0, len(resData))
+	if tx.Error != nil {
+		tx.Rollback()
+		return nil, 0, errors.New("something went wrong: " + tx.Error.Error())
+	}
+	bannerGroups := make(map[string]struct {
+		DataID  int32
+		Feature int32
+		Tags    []int32
+	})
+	for _, banner := range resBanners {
+		key := fmt.Sprintf("%d_%d", banner.DataId, banner.Feature)
+		group, ok := bannerGroups[key]
+		if !ok {
+			group = struct {
+				DataID  int32
+				Feature int32
+				Tags    []int32
+			}{
+				DataID:  banner.DataId,
+				Feature: banner.Feature,
+				Tags:    []int32{}}
+			bannersIds[banner.DataId] = key
+		}
+		group.Tags = append(group.Tags, banner.Tag)
+		bannerGroups[key] = group
+
+	}
+
+	for _, i := range resData {
+		elem := map[string]interface{}{
+			"feature_id": 0,
+			"tag_ids":    []int{},
+			"is_active":  true,
+			"version":    1,
+			"starts_at":  nil,
+			"ends_at":    nil,
+			"updated_at": "",
+			"banner_id":  0,
+			"created_at": "",
+			"content":    map[string]interface{}{},
+		}
+		elem["content"] = i.Content
+		elem["is_active"] = i.IsActive
+		elem["version"] = i.Version
+		elem["starts_at"] = i.StartsAt
+		elem["ends_at"] = i.EndsAt
+		elem["updated_at"] = i.UpdatedAt
+		elem["created_at"] = i.CreatedAt
+		elem["banner_id"] = i.Id
+		elem["tag_ids"] = bannerGroups[bannersIds[i.Id]].Tags
+		elem["feature_id"] = bannerGroups[bannersIds[i.Id]].Feature
+		res = append(res, elem)
+	}
+
+	return res, total, tx.Commit().Error
+}
+
+// sortColumns - столбцы data для полей сортировки models.BannerSort
+var sortColumns = map[string]string{
+	"":                     "id",
+	models.SortById:        "id",
+	models.SortByCreatedAt: "created_at",
+	models.SortByUpdatedAt: "updated_at",
+}
+
+// orderBy упорядочивает баннеры по column, при равенстве - по id в том же направлении
+func orderBy(query *gorm.DB, column string, desc bool) *gorm.DB {
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+	if column == "id" {
+		return query.Order("id" + direction)
+	}
+	return query.Order(column + direction + ", id" + direction)
+}
+
+// whereAfter оставляет баннеры, которые идут после курсора filter.After в порядке по column
+func whereAfter(query *gorm.DB, column string, filter models.BannerFilter) *gorm.DB {
+	if filter.After == nil || filter.After.First() {
+		return query
+	}
+	cmp := " > "
+	if filter.Sort.Desc {
+		cmp = " < "
+	}
+	if column == "id" {
+		return query.Where("id"+cmp+"?", filter.After.Id)
+	}
+	return query.Where("("+column+", id)"+cmp+"(?, ?)", filter.After.Time, filter.After.Id)
+}
+
+// dataKey возвращает ключ баннера d для сортировки по column
+func dataKey(d Data, column string) models.BannerKey {
+	switch column {
+	case "created_at":
+		return models.BannerKey{Time: d.CreatedAt, Id: d.Id}
+	case "updated_at":
+		return models.BannerKey{Time: d.UpdatedAt, Id: d.Id}
+	}
+	return models.BannerKey{Id: d.Id}
+}
+
+// whereRange оставляет строки, у которых column лежит в [from, to]. Нулевая граница не учитывается
+func whereRange(query *gorm.DB, column string, from, to time.Time) *gorm.DB {
+	if !from.IsZero() {
+		query = query.Where(column+" >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where(column+" <= ?", to)
+	}
+	return query
+}
+
+// matchContent оставляет баннеры, содержимое которых удовлетворяет filter
+func matchContent(data []Data, filter models.BannerFilter) []Data {
+	res := make([]Data, 0, len(data))
+	for _, d := range data {
+		if repository.MatchContent(d.Content, filter) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// pageData возвращает часть data после offset длиной не больше limit. Неположительные limit и offset не учитываются
+func pageData(data []Data, limit int, offset int) []Data {
+	if offset > 0 {
+		data = data[min(offset, len(data)):]
+	}
+	if limit > 0 && limit < len(data) {
+		data = data[:limit]
+	}
+	return data
+}
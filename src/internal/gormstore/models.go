@@ -0,0 +1,41 @@
+package gormstore
+
+import (
+	"banner/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Banner struct {
+	DataId  int32 `gorm:"foreignKey:id;references:id"`
+	Feature int32 `gorm:"uniqueIndex:idx_banner_feature_tag"`
+	Tag     int32 `gorm:"uniqueIndex:idx_banner_feature_tag"`
+}
+
+type Data struct {
+	Id        int32          `gorm:"primary_key;auto_increment;index:idx_data_updated_at_id,priority:2;index:idx_data_created_at_id,priority:2"`
+	Content   models.JSONMap `gorm:"type:jsonb;default:'{\"key\": \"value\"}';not null"`
+	IsActive  bool           `gorm:"type:boolean;default:false;"`
+	StartsAt  *time.Time     `gorm:"type:timestamptz"`
+	EndsAt    *time.Time     `gorm:"type:timestamptz"`
+	Version   int32          `gorm:"not null;default:1"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index:idx_data_created_at_id,priority:1"`
+	UpdatedAt time.Time      `gorm:"autoCreateTime;index:idx_data_updated_at_id,priority:1"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// Revision хранит состояние баннера после очередного изменения
+type Revision struct {
+	Id        int32          `gorm:"primary_key;auto_increment"`
+	DataId    int32          `gorm:"uniqueIndex:idx_revision_data_version"`
+	Version   int32          `gorm:"uniqueIndex:idx_revision_data_version"`
+	Feature   int32          `gorm:"not null"`
+	TagIds    models.TagList `gorm:"type:json;not null"`
+	Content   models.JSONMap `gorm:"type:json;not null"`
+	IsActive  bool           `gorm:"type:boolean;default:false;"`
+	StartsAt  *time.Time     `gorm:"type:timestamptz"`
+	EndsAt    *time.Time     `gorm:"type:timestamptz"`
+	Author    string         `gorm:"type:varchar(255)"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,660 @@
+package inmemory
+
+import (
+	"banner/internal/repository"
+	"banner/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultContent - содержимое баннера, созданного без содержимого (как значение по умолчанию колонки в Postgres)
+var defaultContent = models.JSONMap{"key": "value"}
+
+type pair struct {
+	feature int32
+	tag     int32
+}
+
+type banner struct {
+	id       int32
+	feature  int32
+	tags     []int32
+	content  models.JSONMap
+	isActive bool
+	startsAt *time.Time
+	endsAt   *time.Time
+	version  int32
+	created  time.Time
+	updated  time.Time
+	// deleted - момент перемещения баннера в корзину, nil для действующих баннеров
+	deleted *time.Time
+}
+
+// Memory - хранилище баннеров в памяти процесса с той же семантикой, что и Postgres:
+// уникальность пар фича-тэг, история версий, корзина и журнал изменений.
+// Данные теряются при остановке сервиса, а изменения не видны другим экземплярам
+type Memory struct {
+	sync.RWMutex
+	cfg     repository.Config
+	banners map[int32]*banner
+	pairs   map[pair]int32
+	// revisions - версии баннеров по возрастанию
+	revisions map[int32][]models.BannerIdVersionsGet200ResponseInner
+	audit     []models.AuditGet200ResponseInner
+	lastId    int32
+	stop      chan struct{}
+}
+
+func NewMemoryRepository() *Memory {
+	m := newMemoryRepository(repository.LoadConfig())
+	go m.purger()
+	return m
+}
+
+func newMemoryRepository(cfg repository.Config) *Memory {
+	return &Memory{
+		cfg:       cfg,
+		banners:   make(map[int32]*banner),
+		pairs:     make(map[pair]int32),
+		revisions: make(map[int32][]models.BannerIdVersionsGet200ResponseInner),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Ping всегда успешен: хранилище находится в памяти процесса
+func (m *Memory) Ping() error {
+	return nil
+}
+
+// Listen ничего не делает: изменения в памяти процесса не видны другим экземплярам сервиса
+func (m *Memory) Listen(handler func(repository.Change), reset func()) {}
+
+func (m *Memory) Stop() error {
+	close(m.stop)
+	return nil
+}
+
+func (m *Memory) Insert(record *models.InsertData) (int32, error) {
+	if len(record.TagIds) == 0 {
+		return 0, errors.New("can't insert banner: banner must have at least one tag")
+	}
+	m.Lock()
+	defer m.Unlock()
+	if err := m.checkPairs(record.Feature, record.TagIds, 0); err != nil {
+		return 0, fmt.Errorf("can't insert banner: %w", err)
+	}
+	return m.insert(record), nil
+}
+
+// InsertBatch создает баннеры records: сначала проверяет все баннеры, затем создает их
+func (m *Memory) InsertBatch(records []*models.InsertData) ([]int32, error) {
+	m.Lock()
+	defer m.Unlock()
+	batchPairs := make(map[pair]bool)
+	for i, record := range records {
+		if len(record.TagIds) == 0 {
+			return nil, &repository.BatchError{Index: i, Err: errors.New("can't insert banner: banner must have at least one tag")}
+		}
+		err := m.checkPairs(record.Feature, record.TagIds, 0)
+		for _, tag := range record.TagIds {
+			if batchPairs[pair{feature: record.Feature, tag: tag}] {
+				err = repository.ErrConflict
+			}
+			batchPairs[pair{feature: record.Feature, tag: tag}] = true
+		}
+		if err != nil {
+			return nil, &repository.BatchError{Index: i, Err: fmt.Errorf("can't insert banner: %w", err)}
+		}
+	}
+	ids := make([]int32, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, m.insert(record))
+	}
+	return ids, nil
+}
+
+// insert создает проверенный баннер record вместе с первой версией и записью в журнале
+func (m *Memory) insert(record *models.InsertData) int32 {
+	now := time.Now()
+	m.lastId++
+	b := &banner{
+		id:       m.lastId,
+		content:  cloneContent(record.Content),
+		isActive: record.IsActive,
+		startsAt: record.StartsAt,
+		endsAt:   record.EndsAt,
+		version:  1,
+		created:  now,
+		updated:  now,
+	}
+	if b.content == nil {
+		b.content = cloneContent(defaultContent)
+	}
+	m.banners[b.id] = b
+	m.setPairs(b, record.Feature, record.TagIds)
+	after := m.saveRevision(b, record.Actor)
+	m.writeAudit(b.id, repository.ActionCreate, record.AuditInfo, nil, &after)
+	return b.id
+}
+
+// Get возвращает баннер с фичей feature и тэгом tag вместе с флагом активности и окном показа.
+// Реплик у хранилища в памяти нет, поэтому баннер всегда читается в последней версии
+func (m *Memory) Get(feature, tag int32, latest bool) (models.BannerGet200ResponseInner, bool, error) {
+	m.RLock()
+	defer m.RUnlock()
+	id, ok := m.pairs[pair{feature: feature, tag: tag}]
+	if !ok {
+		return models.BannerGet200ResponseInner{}, false, nil
+	}
+	return m.response(m.banners[id]), true, nil
+}
+
+// GetById возвращает баннер id вместе с его версией
+func (m *Memory) GetById(id int32) (models.BannerGet200ResponseInner, bool, error) {
+	m.RLock()
+	defer m.RUnlock()
+	b, ok := m.live(id)
+	if !ok {
+		return models.BannerGet200ResponseInner{}, false, nil
+	}
+	return m.response(b), true, nil
+}
+
+// GetMany возвращает баннеры в том же виде, что и Postgres.GetMany
+func (m *Memory) GetMany(filter models.BannerFilter) ([]map[string]interface{}, int64, error) {
+	m.RLock()
+	defer m.RUnlock()
+	banners := m.sorted(func(b *banner) bool {
+		return b.deleted == nil && matchBanner(b, filter) && repository.MatchContent(b.content, filter)
+	})
+	total := int64(len(banners))
+	key := func(b *banner) models.BannerKey {
+		switch filter.Sort.Field {
+		case models.SortByCreatedAt:
+			return models.BannerKey{Time: b.created, Id: b.id}
+		case models.SortByUpdatedAt:
+			return models.BannerKey{Time: b.updated, Id: b.id}
+		}
+		return models.BannerKey{Id: b.id}
+	}
+	sort.SliceStable(banners, func(i, j int) bool {
+		return filter.Sort.Before(key(banners[i]), key(banners[j]))
+	})
+	if filter.After != nil && !filter.After.First() {
+		banners = slices.DeleteFunc(banners, func(b *banner) bool {
+			return !filter.Sort.Before(filter.After.BannerKey, key(b))
+		})
+	}
+	res := make([]map[string]interface{}, 0)
+	for _, b := range m.page(banners, int(filter.Limit), int(filter.Offset)) {
+		res = append(res, map[string]interface{}{
+			"feature_id": b.feature,
+			"tag_ids":    slices.Clone(b.tags),
+			"is_active":  b.isActive,
+			"version":    b.version,
+			"starts_at":  b.startsAt,
+			"ends_at":    b.endsAt,
+			"updated_at": b.updated,
+			"banner_id":  b.id,
+			"created_at": b.created,
+			"content":    cloneContent(b.content),
+		})
+	}
+	return res, total, nil
+}
+
+// matchBanner проверяет условия filter, кроме условий на содержимое
+func matchBanner(b *banner, filter models.BannerFilter) bool {
+	if len(filter.FeatureIds) > 0 && !slices.Contains(filter.FeatureIds, b.feature) {
+		return false
+	}
+	if len(filter.TagIds) > 0 && !slices.ContainsFunc(b.tags, func(tag int32) bool {
+		return slices.Contains(filter.TagIds, tag)
+	}) {
+		return false
+	}
+	if filter.IsActive != nil && b.isActive != *filter.IsActive {
+		return false
+	}
+	return inRange(b.created, filter.CreatedFrom, filter.CreatedTo) && inRange(b.updated, filter.UpdatedFrom, filter.UpdatedTo)
+}
+
+// inRange проверяет, что t лежит в [from, to]. Нулевая граница не учитывается
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// FindIds возвращает идентификаторы баннеров с фичей featureId и/или тэгом tagId (нулевой фильтр не учитывается)
+func (m *Memory) FindIds(featureId int32, tagId int32) ([]int32, error) {
+	m.RLock()
+	defer m.RUnlock()
+	ids := make([]int32, 0)
+	for _, b := range m.sorted(func(b *banner) bool {
+		if len(b.tags) == 0 || (featureId > 0 && b.feature != featureId) {
+			return false
+		}
+		return tagId <= 0 || slices.Contains(b.tags, tagId)
+	}) {
+		ids = append(ids, b.id)
+	}
+	return ids, nil
+}
+
+// ActiveBanners возвращает активные баннеры, показ которых еще не закончился, начиная с последних измененных.
+// limit <= 0 - без ограничения
+func (m *Memory) ActiveBanners(ctx context.Context, limit int) ([]models.BannerGet200ResponseInner, error) {
+	m.RLock()
+	defer m.RUnlock()
+	now := time.Now()
+	active := m.sorted(func(b *banner) bool {
+		return b.deleted == nil && b.isActive && (b.endsAt == nil || b.endsAt.After(now))
+	})
+	sort.SliceStable(active, func(i, j int) bool {
+		if !active[i].updated.Equal(active[j].updated) {
+			return active[i].updated.After(active[j].updated)
+		}
+		return active[i].id > active[j].id
+	})
+	res := make([]models.BannerGet200ResponseInner, 0, len(active))
+	for _, b := range m.page(active, limit, 0) {
+		res = append(res, m.response(b))
+	}
+	return res, ctx.Err()
+}
+
+// Update изменяет баннер id так же, как Postgres.Update: новые тэги заменяют старые по порядку,
+// а незаданные (нулевые) поля, в том числе is_active=false, не изменяются
+func (m *Memory) Update(id int32, newValue *models.InsertData) (int32, bool, error) {
+	m.Lock()
+	defer m.Unlock()
+	b, ok := m.live(id)
+	if !ok {
+		return 0, false, nil
+	}
+	if newValue.ExpectedVersion != 0 && b.version != newValue.ExpectedVersion {
+		return 0, true, repository.ErrVersionMismatch
+	}
+	startsAt, endsAt := newValue.Window(b.startsAt, b.endsAt)
+	if !models.ValidWindow(startsAt, endsAt) {
+		return 0, true, repository.ErrInvalidWindow
+	}
+	feature, tags := b.feature, slices.Clone(b.tags)
+	if len(newValue.TagIds) > 0 || newValue.Feature > 0 {
+		if newValue.Feature != 0 {
+			feature = newValue.Feature
+		}
+		for i := 0; i < len(newValue.TagIds) && i < len(tags); i++ {
+			tags[i] = newValue.TagIds[i]
+		}
+		for i := len(tags); i < len(newValue.TagIds); i++ {
+			tags = append(tags, newValue.TagIds[i])
+		}
+		if err := m.checkPairs(feature, tags, id); err != nil {
+			return 0, true, fmt.Errorf("can't update banner: %w", err)
+		}
+	}
+	before := m.snapshot(b)
+	if len(m.revisions[id]) == 0 {
+		m.saveRevision(b, "")
+	}
+	m.setPairs(b, feature, tags)
+	if newValue.Content != nil {
+		b.content = cloneContent(newValue.Content)
+	}
+	if newValue.IsActive || newValue.SetIsActive {
+		b.isActive = newValue.IsActive
+	}
+	b.startsAt, b.endsAt = startsAt, endsAt
+	b.updated = time.Now()
+	b.version++
+	after := m.saveRevision(b, newValue.Actor)
+	m.writeAudit(id, repository.ActionUpdate, newValue.AuditInfo, &before, &after)
+	return b.version, true, nil
+}
+
+// Delete перемещает баннер id в корзину. Если expectedVersion не равна нулю, а версия баннера отличается от нее,
+// возвращает ErrVersionMismatch
+func (m *Memory) Delete(id int32, expectedVersion int32, info models.AuditInfo) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+	b, ok := m.live(id)
+	if !ok {
+		return false, nil
+	}
+	if expectedVersion != 0 && b.version != expectedVersion {
+		return true, repository.ErrVersionMismatch
+	}
+	before := m.snapshot(b)
+	// Фича и тэги удаленного баннера остаются в последней версии, по ней баннер восстанавливается из корзины
+	if len(m.revisions[id]) == 0 {
+		m.saveRevision(b, "")
+	}
+	m.setPairs(b, 0, nil)
+	now := time.Now()
+	b.deleted = &now
+	m.writeAudit(id, repository.ActionDelete, info, &before, nil)
+	return true, nil
+}
+
+// Versions возвращает сохраненные версии баннера, начиная с самой новой
+func (m *Memory) Versions(id int32) ([]models.BannerIdVersionsGet200ResponseInner, bool, error) {
+	m.RLock()
+	defer m.RUnlock()
+	if _, ok := m.live(id); !ok {
+		return nil, false, nil
+	}
+	revisions := m.revisions[id]
+	res := make([]models.BannerIdVersionsGet200ResponseInner, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		res = append(res, cloneRevision(revisions[i]))
+	}
+	return res, true, nil
+}
+
+// RestoreVersion делает версию version баннера id текущей. Восстановление сохраняется как новая версия
+func (m *Memory) RestoreVersion(id int32, version int32, info models.AuditInfo) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+	b, ok := m.live(id)
+	if !ok {
+		return false, nil
+	}
+	i := slices.IndexFunc(m.revisions[id], func(rev models.BannerIdVersionsGet200ResponseInner) bool {
+		return rev.Version == version
+	})
+	if i < 0 {
+		return false, nil
+	}
+	rev := m.revisions[id][i]
+	if err := m.checkPairs(rev.FeatureId, rev.TagIds, id); err != nil {
+		return true, fmt.Errorf("can't restore banner: %w", err)
+	}
+	before := m.snapshot(b)
+	m.setPairs(b, rev.FeatureId, rev.TagIds)
+	b.content = cloneContent(rev.Content)
+	b.isActive = rev.IsActive
+	b.startsAt = rev.StartsAt
+	b.endsAt = rev.EndsAt
+	b.updated = time.Now()
+	b.version++
+	after := m.saveRevision(b, info.Actor)
+	m.writeAudit(id, repository.ActionRestoreVersion, info, &before, &after)
+	return true, nil
+}
+
+// Trash возвращает удаленные баннеры, которые еще не были окончательно удалены, начиная с последних
+func (m *Memory) Trash(limit int32, offset int32) ([]models.BannerTrashGet200ResponseInner, error) {
+	m.RLock()
+	defer m.RUnlock()
+	deleted := m.sorted(func(b *banner) bool {
+		return b.deleted != nil
+	})
+	sort.SliceStable(deleted, func(i, j int) bool {
+		return deleted[i].deleted.After(*deleted[j].deleted)
+	})
+	res := make([]models.BannerTrashGet200ResponseInner, 0, len(deleted))
+	for _, b := range m.page(deleted, int(limit), int(offset)) {
+		var last models.BannerIdVersionsGet200ResponseInner
+		if revisions := m.revisions[b.id]; len(revisions) > 0 {
+			last = revisions[len(revisions)-1]
+		}
+		res = append(res, models.BannerTrashGet200ResponseInner{
+			BannerId:  b.id,
+			TagIds:    slices.Clone(last.TagIds),
+			FeatureId: last.FeatureId,
+			Content:   cloneContent(b.content),
+			IsActive:  b.isActive,
+			StartsAt:  b.startsAt,
+			EndsAt:    b.endsAt,
+			CreatedAt: b.created,
+			UpdatedAt: b.updated,
+			DeletedAt: *b.deleted,
+		})
+	}
+	return res, nil
+}
+
+// Restore восстанавливает удаленный баннер с фичей и тэгами из его последней версии.
+// Если какая-то из пар фича-тэг уже занята, возвращает ErrConflict
+func (m *Memory) Restore(id int32, info models.AuditInfo) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+	b, ok := m.banners[id]
+	if !ok || b.deleted == nil {
+		return false, nil
+	}
+	revisions := m.revisions[id]
+	if len(revisions) == 0 {
+		return true, errors.New("can't find last version of banner")
+	}
+	rev := revisions[len(revisions)-1]
+	if err := m.checkPairs(rev.FeatureId, rev.TagIds, id); err != nil {
+		return true, err
+	}
+	m.setPairs(b, rev.FeatureId, rev.TagIds)
+	b.deleted = nil
+	b.updated = time.Now()
+	b.version++
+	after := m.saveRevision(b, info.Actor)
+	m.writeAudit(id, repository.ActionRestore, info, nil, &after)
+	return true, nil
+}
+
+func (m *Memory) purger() {
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-time.After(m.cfg.PurgeInterval):
+		}
+		if _, err := m.Purge(time.Now().Add(-m.cfg.TrashRetention)); err != nil {
+			log.Println("can't purge deleted banners: " + err.Error())
+		}
+	}
+}
+
+// Purge окончательно удаляет баннеры, удаленные раньше before, вместе с их версиями
+func (m *Memory) Purge(before time.Time) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	var purged int64
+	for _, b := range m.sorted(func(b *banner) bool {
+		return b.deleted != nil && b.deleted.Before(before)
+	}) {
+		delete(m.banners, b.id)
+		delete(m.revisions, b.id)
+		m.writeAudit(b.id, repository.ActionPurge, models.AuditInfo{Actor: repository.SystemActor}, nil, nil)
+		purged++
+	}
+	return purged, nil
+}
+
+// Audit возвращает записи журнала изменений, начиная с последних
+func (m *Memory) Audit(filter models.AuditFilter) ([]models.AuditGet200ResponseInner, error) {
+	m.RLock()
+	defer m.RUnlock()
+	limit := int(filter.Limit)
+	if limit <= 0 {
+		limit = repository.DefaultAuditLimit
+	}
+	res := make([]models.AuditGet200ResponseInner, 0)
+	skipped := 0
+	// записи журнала добавляются по порядку, поэтому последние находятся в конце
+	for i := len(m.audit) - 1; i >= 0 && len(res) < limit; i-- {
+		r := m.audit[i]
+		if (filter.BannerId > 0 && r.BannerId != filter.BannerId) ||
+			(filter.Actor != "" && r.Actor != filter.Actor) ||
+			(!filter.From.IsZero() && r.CreatedAt.Before(filter.From)) ||
+			(!filter.To.IsZero() && r.CreatedAt.After(filter.To)) {
+			continue
+		}
+		if skipped < int(filter.Offset) {
+			skipped++
+			continue
+		}
+		r.Before = cloneContent(r.Before)
+		r.After = cloneContent(r.After)
+		r.Diff = cloneContent(r.Diff)
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// live возвращает баннер id, если он не находится в корзине
+func (m *Memory) live(id int32) (*banner, bool) {
+	b, ok := m.banners[id]
+	if !ok || b.deleted != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// sorted возвращает баннеры, удовлетворяющие match, по возрастанию идентификатора
+func (m *Memory) sorted(match func(b *banner) bool) []*banner {
+	res := make([]*banner, 0)
+	for _, b := range m.banners {
+		if match(b) {
+			res = append(res, b)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].id < res[j].id
+	})
+	return res
+}
+
+// page возвращает часть banners после offset длиной не больше limit. Неположительные limit и offset не учитываются
+func (m *Memory) page(banners []*banner, limit int, offset int) []*banner {
+	if offset > 0 {
+		banners = banners[min(offset, len(banners)):]
+	}
+	if limit > 0 && limit < len(banners) {
+		banners = banners[:limit]
+	}
+	return banners
+}
+
+// checkPairs проверяет, что пары feature-tags не повторяются и не заняты другими баннерами, кроме id
+func (m *Memory) checkPairs(feature int32, tags []int32, id int32) error {
+	for i, tag := range tags {
+		if slices.Contains(tags[:i], tag) {
+			return repository.ErrConflict
+		}
+		if owner, ok := m.pairs[pair{feature: feature, tag: tag}]; ok && owner != id {
+			return repository.ErrConflict
+		}
+	}
+	return nil
+}
+
+// setPairs заменяет пары фича-тэг баннера b на feature-tags
+func (m *Memory) setPairs(b *banner, feature int32, tags []int32) {
+	for _, tag := range b.tags {
+		delete(m.pairs, pair{feature: b.feature, tag: tag})
+	}
+	b.feature = feature
+	b.tags = slices.Clone(tags)
+	for _, tag := range b.tags {
+		m.pairs[pair{feature: feature, tag: tag}] = b.id
+	}
+}
+
+func (m *Memory) response(b *banner) models.BannerGet200ResponseInner {
+	tags := slices.Clone(b.tags)
+	slices.Sort(tags)
+	return models.BannerGet200ResponseInner{
+		BannerId:  b.id,
+		TagIds:    tags,
+		FeatureId: b.feature,
+		Content:   cloneContent(b.content),
+		IsActive:  b.isActive,
+		StartsAt:  b.startsAt,
+		EndsAt:    b.endsAt,
+		Version:   b.version,
+		CreatedAt: b.created,
+		UpdatedAt: b.updated,
+	}
+}
+
+// snapshot собирает текущее состояние баннера в одну версию
+func (m *Memory) snapshot(b *banner) models.BannerIdVersionsGet200ResponseInner {
+	tags := slices.Clone(b.tags)
+	slices.Sort(tags)
+	rev := models.BannerIdVersionsGet200ResponseInner{
+		TagIds:   make([]int32, 0, len(tags)),
+		Content:  cloneContent(b.content),
+		IsActive: b.isActive,
+		StartsAt: b.startsAt,
+		EndsAt:   b.endsAt,
+	}
+	if len(tags) > 0 {
+		rev.FeatureId = b.feature
+		rev.TagIds = append(rev.TagIds, tags...)
+	}
+	return rev
+}
+
+// saveRevision сохраняет текущее состояние баннера как новую версию и удаляет версии сверх RevisionsLimit
+func (m *Memory) saveRevision(b *banner, author string) models.BannerIdVersionsGet200ResponseInner {
+	rev := m.snapshot(b)
+	revisions := m.revisions[b.id]
+	rev.Version = 1
+	if len(revisions) > 0 {
+		rev.Version = revisions[len(revisions)-1].Version + 1
+	}
+	rev.Author = author
+	rev.CreatedAt = time.Now()
+	revisions = append(revisions, rev)
+	if limit := m.cfg.RevisionsLimit; limit > 0 && len(revisions) > limit {
+		revisions = slices.Clone(revisions[len(revisions)-limit:])
+	}
+	m.revisions[b.id] = revisions
+	return rev
+}
+
+// writeAudit записывает изменение баннера в журнал. before и after - состояния баннера до и после изменения
+// (nil, если баннера не было или он удален)
+func (m *Memory) writeAudit(id int32, action string, info models.AuditInfo, before, after *models.BannerIdVersionsGet200ResponseInner) {
+	record := models.AuditGet200ResponseInner{
+		Id:        int64(len(m.audit) + 1),
+		BannerId:  id,
+		Actor:     info.Actor,
+		Action:    action,
+		RequestId: info.RequestId,
+		Before:    repository.BannerState(before),
+		After:     repository.BannerState(after),
+		CreatedAt: time.Now(),
+	}
+	record.Diff = repository.DiffStates(record.Before, record.After)
+	m.audit = append(m.audit, record)
+}
+
+// cloneContent копирует содержимое баннера так, как если бы оно было записано в базу и прочитано из нее,
+// чтобы изменения у вызывающего не затрагивали хранилище
+func cloneContent(content models.JSONMap) models.JSONMap {
+	if content == nil {
+		return nil
+	}
+	bytes, err := json.Marshal(content)
+	if err != nil {
+		return nil
+	}
+	var res models.JSONMap
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return nil
+	}
+	return res
+}
+
+func cloneRevision(rev models.BannerIdVersionsGet200ResponseInner) models.BannerIdVersionsGet200ResponseInner {
+	rev.TagIds = slices.Clone(rev.TagIds)
+	rev.Content = cloneContent(rev.Content)
+	return rev
+}
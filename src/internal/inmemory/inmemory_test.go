@@ -0,0 +1,13 @@
+package inmemory
+
+import (
+	"banner/internal/repository"
+	"banner/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, cfg repository.Config) repository.Repository {
+		return newMemoryRepository(cfg)
+	})
+}
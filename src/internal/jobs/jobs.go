@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"banner/models"
+	"sync"
+	"time"
+)
+
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// finishedTTL - время, в течение которого хранится информация о завершенной задаче
+const finishedTTL = time.Hour
+
+// Manager выполняет фоновые задачи над набором баннеров и хранит их состояние в памяти
+type Manager struct {
+	sync.RWMutex
+	wg     sync.WaitGroup
+	lastId int32
+	jobs   map[int32]*models.JobsIdGet200Response
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		jobs: make(map[int32]*models.JobsIdGet200Response),
+	}
+}
+
+// Enqueue создает задачу, которая в фоне вызывает fn для каждого из ids, и возвращает ее идентификатор.
+// fn возвращает false, если баннер уже был удален
+func (m *Manager) Enqueue(ids []int32, fn func(id int32) (bool, error)) int32 {
+	m.Lock()
+	m.removeFinished()
+	m.lastId++
+	now := time.Now()
+	job := &models.JobsIdGet200Response{
+		JobId:     m.lastId,
+		Status:    StatusQueued,
+		Total:     int32(len(ids)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.jobs[job.JobId] = job
+	m.Unlock()
+
+	m.wg.Add(1)
+	go m.run(job, ids, fn)
+	return job.JobId
+}
+
+// Get возвращает копию состояния задачи
+func (m *Manager) Get(id int32) (models.JobsIdGet200Response, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return models.JobsIdGet200Response{}, false
+	}
+	return *job, true
+}
+
+// Wait ожидает завершения всех запущенных задач
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+func (m *Manager) run(job *models.JobsIdGet200Response, ids []int32, fn func(id int32) (bool, error)) {
+	defer m.wg.Done()
+	m.update(job, func() {
+		job.Status = StatusRunning
+	})
+	for _, id := range ids {
+		found, err := fn(id)
+		m.update(job, func() {
+			job.Processed++
+			switch {
+			case err != nil:
+				job.Failed++
+				job.Error = err.Error()
+			case found:
+				job.Deleted++
+			}
+		})
+	}
+	m.update(job, func() {
+		if job.Failed > 0 {
+			job.Status = StatusFailed
+		} else {
+			job.Status = StatusDone
+		}
+	})
+}
+
+func (m *Manager) update(job *models.JobsIdGet200Response, fn func()) {
+	m.Lock()
+	defer m.Unlock()
+	fn()
+	job.UpdatedAt = time.Now()
+}
+
+// removeFinished удаляет завершенные задачи старше finishedTTL. Вызывается под блокировкой
+func (m *Manager) removeFinished() {
+	for id, job := range m.jobs {
+		finished := job.Status == StatusDone || job.Status == StatusFailed
+		if finished && time.Since(job.UpdatedAt) > finishedTTL {
+			delete(m.jobs, id)
+		}
+	}
+}
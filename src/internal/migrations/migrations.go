@@ -0,0 +1,312 @@
+// Package migrations применяет к базе Postgres пронумерованные SQL-миграции, встроенные в бинарный файл.
+// Миграция NNNN_name состоит из файлов sql/NNNN_name.up.sql и sql/NNNN_name.down.sql, примененные
+// версии хранятся в таблице schema_migrations
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey - ключ рекомендательной блокировки (pg_advisory_lock): миграции одновременно применяет
+// только один процесс, даже если несколько экземпляров сервиса запущены сразу
+const lockKey int64 = 7236450581
+
+// ErrSchemaBehind - в базе применены не все миграции, известные сервису
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status - состояние миграции в базе. AppliedAt равно nil, если миграция не применена
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// appliedMigration - строка таблицы schema_migrations
+type appliedMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// step - применение (up) или откат миграции
+type step struct {
+	Migration
+	up bool
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New создает мигратор для базы db со встроенными миграциями
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load читает миграции из каталога sql и упорядочивает их по версии
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, errors.New("can't read migrations: " + err.Error())
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("bad migration version in %s", entry.Name())
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", m.Name, match[2])
+		}
+		content, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, errors.New("can't read migration: " + err.Error())
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Latest возвращает версию последней известной миграции
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down откатывает последнюю примененную миграцию
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.locked(ctx, func(conn *gorm.DB, applied map[int64]time.Time) error {
+		var last int64
+		for version := range applied {
+			if version > last {
+				last = version
+			}
+		}
+		if last == 0 {
+			return nil
+		}
+		migration, ok := m.find(last)
+		if !ok {
+			return fmt.Errorf("can't roll back unknown migration %d", last)
+		}
+		return apply(conn, step{Migration: migration})
+	})
+}
+
+// To применяет миграции до версии version включительно и откатывает примененные миграции с большими
+// версиями. Версия 0 откатывает все миграции
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if _, ok := m.find(version); !ok && version != 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.locked(ctx, func(conn *gorm.DB, applied map[int64]time.Time) error {
+		steps, err := plan(m.migrations, applied, version)
+		if err != nil {
+			return err
+		}
+		for _, s := range steps {
+			if err := apply(conn, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status возвращает состояние всех известных миграций
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if at, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		res = append(res, status)
+	}
+	return res, nil
+}
+
+// Check возвращает ErrSchemaBehind, если в базе применены не все известные миграции
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	pending := make([]string, 0)
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// plan составляет шаги перехода к версии target: сначала откат примененных миграций с большими версиями
+// (от новых к старым), затем применение недостающих миграций до target (от старых к новым)
+func plan(migrations []Migration, applied map[int64]time.Time, target int64) ([]step, error) {
+	known := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+	rollback := make([]int64, 0)
+	for version := range applied {
+		if version > target {
+			rollback = append(rollback, version)
+		}
+	}
+	sort.Slice(rollback, func(i, j int) bool {
+		return rollback[i] > rollback[j]
+	})
+	steps := make([]step, 0)
+	for _, version := range rollback {
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("can't roll back unknown migration %d", version)
+		}
+		steps = append(steps, step{Migration: migration})
+	}
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+			steps = append(steps, step{Migration: migration, up: true})
+		}
+	}
+	return steps, nil
+}
+
+// locked выполняет fc на отдельном подключении под рекомендательной блокировкой. applied - версии,
+// примененные к моменту получения блокировки
+func (m *Migrator) locked(ctx context.Context, fc func(conn *gorm.DB, applied map[int64]time.Time) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+			return errors.New("can't lock migrations: " + err.Error())
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		if err := conn.AutoMigrate(&appliedMigration{}); err != nil {
+			return errors.New("can't create migrations table: " + err.Error())
+		}
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		return fc(conn, applied)
+	})
+}
+
+// applied возвращает версии примененных миграций и время их применения
+func (m *Migrator) applied(db *gorm.DB) (map[int64]time.Time, error) {
+	res := make(map[int64]time.Time)
+	if !db.Migrator().HasTable(&appliedMigration{}) {
+		return res, nil
+	}
+	var rows []appliedMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, errors.New("can't read applied migrations: " + err.Error())
+	}
+	for _, row := range rows {
+		res[row.Version] = row.AppliedAt
+	}
+	return res, nil
+}
+
+// apply выполняет шаг в отдельной транзакции вместе с записью в schema_migrations
+func apply(conn *gorm.DB, s step) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if !s.up {
+			if err := tx.Exec(s.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&appliedMigration{}, s.Version).Error
+		}
+		if err := tx.Exec(s.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&appliedMigration{Version: s.Version, Name: s.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		direction := "apply"
+		if !s.up {
+			direction = "roll back"
+		}
+		return fmt.Errorf("can't %s migration %04d_%s: %w", direction, s.Version, s.Name, err)
+	}
+	return nil
+}
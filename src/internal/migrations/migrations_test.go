@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %s has version %d; want %d", m.Name, m.Version, i+1)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	cases := map[string]fstest.MapFS{
+		"missing down":   {"sql/0001_init.up.sql": file},
+		"same version":   {"sql/0001_a.up.sql": file, "sql/0001_a.down.sql": file, "sql/0001_b.up.sql": file, "sql/0001_b.down.sql": file},
+		"bad file name":  {"sql/init.sql": file},
+		"zero version":   {"sql/0000_init.up.sql": file, "sql/0000_init.down.sql": file},
+		"empty down sql": {"sql/0001_init.up.sql": file, "sql/0001_init.down.sql": &fstest.MapFile{Data: []byte("\n")}},
+	}
+	for name, fsys := range cases {
+		if _, err := load(fsys); err == nil {
+			t.Errorf("%s: load succeeded", name)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	applied := func(versions ...int64) map[int64]time.Time {
+		res := make(map[int64]time.Time)
+		for _, v := range versions {
+			res[v] = time.Now()
+		}
+		return res
+	}
+	type want struct {
+		version int64
+		up      bool
+	}
+	cases := []struct {
+		applied map[int64]time.Time
+		target  int64
+		want    []want
+	}{
+		{applied(), 3, []want{{1, true}, {2, true}, {3, true}}},
+		{applied(1), 2, []want{{2, true}}},
+		{applied(1, 2, 3), 1, []want{{3, false}, {2, false}}},
+		{applied(1, 2, 3), 0, []want{{3, false}, {2, false}, {1, false}}},
+		{applied(1, 3), 3, []want{{2, true}}},
+		{applied(1, 2), 2, []want{}},
+	}
+	for _, c := range cases {
+		steps, err := plan(migrations, c.applied, c.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]want, 0, len(steps))
+		for _, s := range steps {
+			got = append(got, want{s.Version, s.up})
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("plan(%v, %d) = %v; want %v", c.applied, c.target, got, c.want)
+		}
+	}
+
+	if _, err := plan(migrations, applied(1, 4), 1); err == nil {
+		t.Error("plan rolled back unknown migration")
+	}
+}
@@ -0,0 +1,23 @@
+-- Базы, созданные до перехода на миграции, уже содержат таблицы: создаем только недостающее
+CREATE TABLE IF NOT EXISTS data (
+	id serial PRIMARY KEY,
+	content json NOT NULL DEFAULT '{"key": "value"}',
+	is_active boolean DEFAULT false,
+	created_at timestamptz,
+	updated_at timestamptz
+);
+
+ALTER TABLE data ADD COLUMN IF NOT EXISTS starts_at timestamptz;
+ALTER TABLE data ADD COLUMN IF NOT EXISTS ends_at timestamptz;
+ALTER TABLE data ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE data ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_data_deleted_at ON data (deleted_at);
+
+CREATE TABLE IF NOT EXISTS banners (
+	data_id integer,
+	feature integer,
+	tag integer
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_banner_feature_tag ON banners (feature, tag);
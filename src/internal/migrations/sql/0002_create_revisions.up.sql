@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS revisions (
+	id serial PRIMARY KEY,
+	data_id integer,
+	version integer,
+	feature integer NOT NULL,
+	tag_ids json NOT NULL,
+	content json NOT NULL,
+	is_active boolean DEFAULT false,
+	starts_at timestamptz,
+	ends_at timestamptz,
+	author varchar(255),
+	created_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revision_data_version ON revisions (data_id, version);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+	id bigserial PRIMARY KEY,
+	banner_id integer NOT NULL,
+	actor varchar(255),
+	action varchar(32) NOT NULL,
+	request_id varchar(64),
+	before json,
+	after json,
+	diff json,
+	created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_banner_id ON audit_log (banner_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещено
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+	FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_data_content;
+
+ALTER TABLE data ALTER COLUMN content DROP DEFAULT;
+ALTER TABLE data ALTER COLUMN content TYPE json USING content::json;
+ALTER TABLE data ALTER COLUMN content SET DEFAULT '{"key": "value"}';
@@ -0,0 +1,6 @@
+-- jsonb позволяет индексировать содержимое баннеров и искать по нему (GET /banner?content.title=...)
+ALTER TABLE data ALTER COLUMN content DROP DEFAULT;
+ALTER TABLE data ALTER COLUMN content TYPE jsonb USING content::jsonb;
+ALTER TABLE data ALTER COLUMN content SET DEFAULT '{"key": "value"}';
+
+CREATE INDEX IF NOT EXISTS idx_data_content ON data USING gin (content);
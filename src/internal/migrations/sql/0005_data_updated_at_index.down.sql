@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_data_updated_at_id;
@@ -0,0 +1,2 @@
+-- Индекс для постраничного вывода GET /banner по курсору в порядке (updated_at, id)
+CREATE INDEX IF NOT EXISTS idx_data_updated_at_id ON data (updated_at, id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_data_created_at_id;
@@ -0,0 +1,2 @@
+-- Индекс для сортировки и постраничного вывода GET /banner в порядке (created_at, id)
+CREATE INDEX IF NOT EXISTS idx_data_created_at_id ON data (created_at, id);
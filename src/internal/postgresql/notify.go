@@ -0,0 +1,138 @@
+package postgresql
+
+import (
+	"banner/internal/gormstore"
+	"banner/internal/repository"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// changesChannel - канал LISTEN/NOTIFY, в который отправляются изменения баннеров
+	changesChannel = "banner_changes"
+
+	listenerMinDelay = time.Second
+	listenerMaxDelay = 30 * time.Second
+)
+
+// newInstanceId возвращает случайный идентификатор экземпляра сервиса
+func newInstanceId() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return time.Now().Format(time.RFC3339Nano)
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// notifications сообщает, что уведомления об изменениях включены: они отправляются и принимаются
+// только при заданной строке подключения
+func (p *Postgres) notifications() bool {
+	return p.dsn != ""
+}
+
+// Notify отправляет уведомление об изменении баннера в рамках транзакции tx.
+// Postgres доставляет его слушателям только после коммита транзакции
+func (p *Postgres) Notify(tx *gorm.DB, id int32, before, after *gormstore.Revision) error {
+	if !p.notifications() {
+		return nil
+	}
+	change := repository.Change{Id: id, Origin: p.instance}
+	for _, rev := range []*gormstore.Revision{before, after} {
+		if rev == nil {
+			continue
+		}
+		if !slices.Contains(change.Features, rev.Feature) {
+			change.Features = append(change.Features, rev.Feature)
+		}
+		for _, tag := range rev.TagIds {
+			if !slices.Contains(change.Tags, tag) {
+				change.Tags = append(change.Tags, tag)
+			}
+		}
+	}
+	if len(change.Features) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return errors.New("can't notify about banner change: " + err.Error())
+	}
+	if err := tx.Exec("SELECT pg_notify(?, ?)", changesChannel, string(payload)).Error; err != nil {
+		return errors.New("can't notify about banner change: " + err.Error())
+	}
+	return nil
+}
+
+// Listen получает изменения баннеров, выполненные другими экземплярами сервиса, и передает их в handler.
+// При потере соединения слушатель переподключается, а после переподключения вызывает reset,
+// так как уведомления, отправленные без соединения, потеряны. Останавливается вместе с Postgres
+func (p *Postgres) Listen(handler func(repository.Change), reset func()) {
+	if !p.notifications() {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-p.stop
+		cancel()
+	}()
+	go func() {
+		delay := listenerMinDelay
+		connected := false
+		for {
+			err := p.listen(ctx, handler, func() {
+				if connected {
+					reset()
+				}
+				connected = true
+				delay = listenerMinDelay
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			log.Println("banner changes listener disconnected: " + err.Error())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, listenerMaxDelay)
+		}
+	}()
+}
+
+// listen подписывается на changesChannel и обрабатывает уведомления до ошибки соединения
+func (p *Postgres) listen(ctx context.Context, handler func(repository.Change), connected func()) error {
+	conn, err := pgx.Connect(ctx, p.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+changesChannel); err != nil {
+		return err
+	}
+	connected()
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var change repository.Change
+		if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+			log.Println("can't parse banner change: " + err.Error())
+			continue
+		}
+		if change.Origin == p.instance {
+			continue
+		}
+		handler(change)
+	}
+}
@@ -0,0 +1,158 @@
+package postgresql
+
+import (
+	"banner/internal/gormstore"
+	"banner/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const defaultReplicaCheckInterval = 5 * time.Second
+
+// replica - реплика базы только для чтения. Запросы направляются только на исправные реплики
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// openReplicas подключается к репликам из POSTGRES_REPLICAS (строки подключения через точку с запятой).
+// Недоступная при запуске реплика не мешает старту: она подключится, когда пройдет проверку
+func openReplicas() ([]*gorm.DB, error) {
+	dbs := make([]*gorm.DB, 0)
+	for _, dsn := range strings.Split(os.Getenv("POSTGRES_REPLICAS"), ";") {
+		if strings.TrimSpace(dsn) == "" {
+			continue
+		}
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:               logger.Default.LogMode(logger.Silent),
+			TranslateError:       true,
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", len(dbs)+1, err)
+		}
+		rawDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", len(dbs)+1, err)
+		}
+		rawDB.SetMaxOpenConns(128)
+		rawDB.SetMaxIdleConns(256)
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
+
+// replicaCheckInterval возвращает период проверки реплик из POSTGRES_REPLICA_CHECK_INTERVAL
+func replicaCheckInterval() time.Duration {
+	val := os.Getenv("POSTGRES_REPLICA_CHECK_INTERVAL")
+	if val == "" {
+		return defaultReplicaCheckInterval
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		panic("Can't parse POSTGRES_REPLICA_CHECK_INTERVAL: " + val)
+	}
+	return d
+}
+
+// addReplicas добавляет реплики для чтения и сразу проверяет их доступность
+func (p *Postgres) addReplicas(dbs ...*gorm.DB) {
+	for _, db := range dbs {
+		p.replicas = append(p.replicas, &replica{name: fmt.Sprintf("replica %d", len(p.replicas)+1), db: db})
+	}
+	p.checkReplicas()
+}
+
+func (p *Postgres) startReplicaChecks(interval time.Duration) {
+	if len(p.replicas) == 0 {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(interval):
+			}
+			p.checkReplicas()
+		}
+	}()
+}
+
+// checkReplicas проверяет соединение с каждой репликой и включает или исключает ее из ротации
+func (p *Postgres) checkReplicas() {
+	for _, r := range p.replicas {
+		r.setHealthy(gormstore.Ping(r.db))
+	}
+}
+
+// setHealthy меняет состояние реплики. Смена состояния записывается в лог
+func (r *replica) setHealthy(err error) {
+	if err == nil {
+		if !r.healthy.Swap(true) {
+			log.Println(r.name + " is up")
+		}
+		return
+	}
+	if r.healthy.Swap(false) {
+		log.Println(r.name + " is down: " + err.Error())
+	}
+}
+
+// replica выбирает следующую по кругу исправную реплику. Если исправных реплик нет, возвращает nil
+// и запрос выполняется на основной базе
+func (p *Postgres) replica() *replica {
+	n := uint64(len(p.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := p.nextReplica.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := p.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// Read выполняет чтение fc на реплике, а если реплик нет или реплика вернула ошибку - на основной базе
+// (реплика может отменить запрос, например, из-за конфликта с применением изменений). Из ротации до следующей
+// успешной проверки реплику исключает только ошибка соединения: ошибка самого запроса не говорит о ее неисправности
+func (p *Postgres) Read(fc func(db *gorm.DB) error) error {
+	if r := p.replica(); r != nil {
+		err := fc(r.db)
+		if err == nil {
+			return nil
+		}
+		if repository.IsConnectionError(err) {
+			r.setHealthy(err)
+		}
+	}
+	return fc(p.Db)
+}
+
+// closeReplicas закрывает соединения со всеми репликами и возвращает ошибки закрытия
+func (p *Postgres) closeReplicas() error {
+	errs := make([]error, 0)
+	for _, r := range p.replicas {
+		val, err := r.db.DB()
+		if err != nil {
+			errs = append(errs, errors.New("failed to get database; error: "+err.Error()))
+			continue
+		}
+		if err := val.Close(); err != nil {
+			errs = append(errs, errors.New("failed to close "+r.name+" connection; error: "+err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,126 @@
+package postgresql
+
+import (
+	"banner/internal/repository"
+	"banner/internal/sqlite"
+	"banner/models"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openSource создает базу SQLite с единственным баннером (фича 1, тэг 1), title которого равен name,
+// и хранилище Postgres поверх нее без уведомлений об изменениях
+func openSource(t *testing.T, name string) *Postgres {
+	t.Helper()
+	s, err := sqlite.Open(filepath.Join(t.TempDir(), name+".db"), repository.Config{RevisionsLimit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newRepository(s.Db, "", repository.Config{RevisionsLimit: 3})
+	_, err = p.Insert(&models.InsertData{Feature: 1, TagIds: []int32{1}, Content: models.JSONMap{"title": name}, IsActive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReplicas(t *testing.T) {
+	p := openSource(t, "primary")
+	first, second := openSource(t, "first"), openSource(t, "second")
+	p.addReplicas(first.Db, second.Db)
+	t.Cleanup(func() { p.Stop() })
+
+	title := func(latest bool) interface{} {
+		t.Helper()
+		banner, found, err := p.Get(1, 1, latest)
+		if err != nil || !found {
+			t.Fatalf("Get(1, 1, %v) = %v, %v", latest, found, err)
+		}
+		return banner.Content["title"]
+	}
+	listTitle := func() interface{} {
+		t.Helper()
+		banners, _, err := p.GetMany(models.BannerFilter{})
+		if err != nil || len(banners) != 1 {
+			t.Fatalf("GetMany() = %v, %v", banners, err)
+		}
+		return banners[0]["content"].(models.JSONMap)["title"]
+	}
+
+	// чтения распределяются по репликам по кругу, latest читает основную базу
+	seen := map[interface{}]int{}
+	for i := 0; i < 4; i++ {
+		seen[title(false)]++
+		seen[listTitle()]++
+	}
+	if seen["first"] != 4 || seen["second"] != 4 {
+		t.Fatalf("reads by replica = %v", seen)
+	}
+	if got := title(true); got != "primary" {
+		t.Fatalf("latest read from %v", got)
+	}
+
+	// неисправная реплика исключается из ротации
+	p.replicas[0].healthy.Store(false)
+	for i := 0; i < 3; i++ {
+		if got := title(false); got != "second" {
+			t.Fatalf("read from %v with first replica down", got)
+		}
+	}
+
+	// ошибка запроса на реплике повторяется на основной базе, но реплика остается в ротации
+	var replicaErr error
+	failReads(t, second.Db, &replicaErr)
+	replicaErr = errors.New("canceling statement due to conflict with recovery")
+	if got := title(false); got != "primary" {
+		t.Fatalf("read from %v with second replica failed", got)
+	}
+	if !p.replicas[1].healthy.Load() {
+		t.Fatal("replica is excluded after query error")
+	}
+
+	// после ошибки соединения реплика исключается до следующей проверки
+	replicaErr = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	if got := title(false); got != "primary" {
+		t.Fatalf("read from %v with second replica disconnected", got)
+	}
+	if p.replicas[1].healthy.Load() {
+		t.Fatal("disconnected replica is still healthy")
+	}
+	closeDB(t, second.Db)
+	p.checkReplicas()
+	if !p.replicas[0].healthy.Load() || p.replicas[1].healthy.Load() {
+		t.Fatal("replica health is not updated by check")
+	}
+	if got := title(false); got != "first" {
+		t.Fatalf("read from %v after check", got)
+	}
+}
+
+// failReads завершает запросы к db ошибкой *err, если она задана
+func failReads(t *testing.T, db *gorm.DB, err *error) {
+	t.Helper()
+	register := db.Callback().Query().Before("gorm:query").Register("test:fail_reads", func(tx *gorm.DB) {
+		if *err != nil {
+			tx.AddError(*err)
+		}
+	})
+	if register != nil {
+		t.Fatal(register)
+	}
+}
+
+func closeDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	val, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := val.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"banner/models"
+	"encoding/json"
+	"reflect"
+)
+
+// Действия, записываемые в журнал изменений
+const (
+	ActionCreate         = "create"
+	ActionUpdate         = "update"
+	ActionDelete         = "delete"
+	ActionRestore        = "restore"
+	ActionRestoreVersion = "restore_version"
+	ActionPurge          = "purge"
+)
+
+const (
+	DefaultAuditLimit = 100
+	// SystemActor - автор изменений, которые сервис выполняет сам (например, очистка корзины)
+	SystemActor = "system"
+)
+
+// BannerState переводит состояние баннера в JSON-объект для журнала (nil, если баннера не было или он удален)
+func BannerState(rev *models.BannerIdVersionsGet200ResponseInner) models.JSONMap {
+	if rev == nil {
+		return nil
+	}
+	state := map[string]interface{}{
+		"feature_id": rev.FeatureId,
+		"tag_ids":    rev.TagIds,
+		"content":    map[string]interface{}(rev.Content),
+		"is_active":  rev.IsActive,
+		"starts_at":  rev.StartsAt,
+		"ends_at":    rev.EndsAt,
+	}
+	// Приводим значения к типам, которые получатся после чтения из базы, чтобы их можно было сравнивать
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	var res models.JSONMap
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return nil
+	}
+	return res
+}
+
+// DiffStates возвращает поля, которые отличаются в состояниях before и after, в виде {"поле": {"before": ..., "after": ...}}
+func DiffStates(before, after models.JSONMap) models.JSONMap {
+	diff := make(models.JSONMap)
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			diff[key] = map[string]interface{}{"before": before[key], "after": value}
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok && value != nil {
+			diff[key] = map[string]interface{}{"before": value, "after": nil}
+		}
+	}
+	return diff
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRevisionsLimit     = 10
+	defaultTrashRetention     = 30 * 24 * time.Hour
+	defaultTrashPurgeInterval = time.Hour
+)
+
+// Config - настройки истории изменений и корзины, общие для всех реализаций Repository
+type Config struct {
+	// RevisionsLimit - количество хранимых версий баннера (REVISIONS_LIMIT)
+	RevisionsLimit int
+	// TrashRetention - сколько удаленный баннер хранится в корзине (TRASH_RETENTION)
+	TrashRetention time.Duration
+	// PurgeInterval - как часто корзина очищается от старых баннеров (TRASH_PURGE_INTERVAL)
+	PurgeInterval time.Duration
+}
+
+func LoadConfig() Config {
+	return Config{
+		RevisionsLimit: revisionsLimit(),
+		TrashRetention: parseDuration("TRASH_RETENTION", defaultTrashRetention),
+		PurgeInterval:  parseDuration("TRASH_PURGE_INTERVAL", defaultTrashPurgeInterval),
+	}
+}
+
+// parseDuration читает длительность из переменной окружения name, если она не задана - возвращает def
+func parseDuration(name string, def time.Duration) time.Duration {
+	val := os.Getenv(name)
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		panic("Can't parse " + name + ": " + val)
+	}
+	return d
+}
+
+// revisionsLimit возвращает количество хранимых версий баннера из REVISIONS_LIMIT
+func revisionsLimit() int {
+	val := os.Getenv("REVISIONS_LIMIT")
+	if val == "" {
+		return defaultRevisionsLimit
+	}
+	limit, err := strconv.Atoi(val)
+	if err != nil || limit <= 0 {
+		panic("Can't parse REVISIONS_LIMIT: " + val)
+	}
+	return limit
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"banner/models"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// ContentAlternatives переводит условие на поле содержимого path=value в объекты, хотя бы один из которых
+// должно содержать содержимое баннера: {"title": "Sale"} для content.title=Sale. Если value - JSON-число,
+// true, false или null, подходит и поле с таким значением: content.priority=5 находит и 5, и "5"
+func ContentAlternatives(path string, value string) []models.JSONMap {
+	res := []models.JSONMap{contentPath(path, value)}
+	var scalar interface{}
+	if err := json.Unmarshal([]byte(value), &scalar); err == nil {
+		switch scalar.(type) {
+		case float64, bool, nil:
+			res = append(res, contentPath(path, scalar))
+		}
+	}
+	return res
+}
+
+// contentPath создает объект с единственным полем path (через точку) со значением value
+func contentPath(path string, value interface{}) models.JSONMap {
+	keys := strings.Split(path, ".")
+	for i := len(keys) - 1; i > 0; i-- {
+		value = map[string]interface{}{keys[i]: value}
+	}
+	return models.JSONMap{keys[0]: value}
+}
+
+// MatchContent проверяет, что содержимое баннера удовлетворяет условиям filter на содержимое
+// так же, как в Postgres (см. Contains)
+func MatchContent(content models.JSONMap, filter models.BannerFilter) bool {
+	if filter.ContentContains != nil && !Contains(content, filter.ContentContains) {
+		return false
+	}
+	for path, value := range filter.ContentFields {
+		matched := false
+		for _, alternative := range ContentAlternatives(path, value) {
+			if Contains(content, alternative) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains проверяет, что JSON-значение have содержит want, как оператор @> для jsonb: объект содержит
+// поля want с содержащимися значениями, массив содержит каждый элемент want, остальные значения равны
+func Contains(have, want interface{}) bool {
+	switch want := asJSON(want).(type) {
+	case map[string]interface{}:
+		have, ok := asJSON(have).(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range want {
+			field, ok := have[key]
+			if !ok || !Contains(field, value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		have, ok := asJSON(have).([]interface{})
+		if !ok {
+			return false
+		}
+		for _, value := range want {
+			found := false
+			for _, elem := range have {
+				if Contains(elem, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(asJSON(have), want)
+	}
+}
+
+// asJSON приводит models.JSONMap к типам, которые возвращает encoding/json
+func asJSON(value interface{}) interface{} {
+	if m, ok := value.(models.JSONMap); ok {
+		return map[string]interface{}(m)
+	}
+	return value
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"banner/models"
+	"encoding/json"
+	"testing"
+)
+
+func TestContains(t *testing.T) {
+	cases := []struct {
+		have, want string
+		contains   bool
+	}{
+		{`{"a": 1, "b": 2}`, `{"a": 1}`, true},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{`{"a": {"b": "c", "d": 1}}`, `{"a": {"b": "c"}}`, true},
+		{`{"a": [1, 2, 3]}`, `{"a": [3, 1]}`, true},
+		{`{"a": [1, 2]}`, `{"a": [4]}`, false},
+		{`{"a": [{"b": 1, "c": 2}]}`, `{"a": [{"b": 1}]}`, true},
+		{`{"a": "1"}`, `{"a": 1}`, false},
+		{`{"a": null}`, `{"a": null}`, true},
+		{`{"a": {}}`, `{"a": {}}`, true},
+		{`{"a": []}`, `{"a": {}}`, false},
+	}
+	for _, c := range cases {
+		var have, want models.JSONMap
+		if err := json.Unmarshal([]byte(c.have), &have); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(c.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if got := Contains(have, want); got != c.contains {
+			t.Errorf("Contains(%s, %s) = %v; want %v", c.have, c.want, got, c.contains)
+		}
+	}
+}
+
+func TestContentAlternatives(t *testing.T) {
+	alternatives := ContentAlternatives("meta.priority", "5")
+	if len(alternatives) != 2 || jsonString(t, alternatives[0]) != `{"meta":{"priority":"5"}}` || jsonString(t, alternatives[1]) != `{"meta":{"priority":5}}` {
+		t.Fatalf("ContentAlternatives(meta.priority, 5) = %v", alternatives)
+	}
+	if alternatives := ContentAlternatives("title", `{"a":1}`); len(alternatives) != 1 {
+		t.Fatalf("ContentAlternatives with object value = %v; want only string", alternatives)
+	}
+}
+
+func jsonString(t *testing.T, value models.JSONMap) string {
+	t.Helper()
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bytes)
+}
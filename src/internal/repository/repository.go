@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"banner/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrConflict возвращается, если пара фича-тэг баннера уже занята другим баннером
+var ErrConflict = errors.New("feature and tag pair is already used by another banner")
+
+// ErrVersionMismatch возвращается, если версия баннера в хранилище отличается от ожидаемой клиентом
+var ErrVersionMismatch = errors.New("banner version has changed")
+
+// ErrInvalidWindow возвращается, если после изменения окончание окна показа баннера не позже его начала
+var ErrInvalidWindow = errors.New("banner ends_at must be after starts_at")
+
+// IsConnectionError сообщает, что ошибка вызвана соединением с базой, а не запросом
+func IsConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
+// BatchError - ошибка создания баннера с номером Index (с нуля) в InsertBatch
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("banner %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Repository - хранилище баннеров. Реализации должны вести себя одинаково: пара фича-тэг принадлежит
+// не более чем одному баннеру, каждое изменение сохраняется как новая версия и записывается в журнал,
+// удаленные баннеры попадают в корзину
+type Repository interface {
+	// Insert создает баннер и возвращает его идентификатор
+	Insert(record *models.InsertData) (int32, error)
+	// InsertBatch создает баннеры records в одной транзакции: либо все, либо ни одного. Возвращает их
+	// идентификаторы в порядке records. Ошибка создания баннера возвращается как *BatchError
+	InsertBatch(records []*models.InsertData) ([]int32, error)
+	// Get возвращает баннер с фичей feature и тэгом tag. Если latest, баннер читается в последней
+	// сохраненной версии, иначе может быть прочитан с реплики, отстающей от основной базы
+	Get(feature, tag int32, latest bool) (models.BannerGet200ResponseInner, bool, error)
+	// GetById возвращает баннер id вместе с его версией
+	GetById(id int32) (models.BannerGet200ResponseInner, bool, error)
+	// GetMany возвращает страницу баннеров, удовлетворяющих filter, и общее количество таких баннеров.
+	// Условия на содержимое проверяются как в MatchContent. Нулевые limit и offset не ограничивают выборку
+	GetMany(filter models.BannerFilter) ([]map[string]interface{}, int64, error)
+	// FindIds возвращает идентификаторы баннеров с фичей featureId и/или тэгом tagId
+	FindIds(featureId int32, tagId int32) ([]int32, error)
+	// ActiveBanners возвращает активные баннеры, показ которых еще не закончился, начиная с последних измененных
+	ActiveBanners(ctx context.Context, limit int) ([]models.BannerGet200ResponseInner, error)
+	// Update изменяет баннер id. Незаданные (нулевые) поля newValue не изменяются. Если окно показа
+	// с учетом сохраненных границ получается пустым, возвращает ErrInvalidWindow
+	// Возвращает новую версию баннера
+	Update(id int32, newValue *models.InsertData) (int32, bool, error)
+	// Delete перемещает баннер id в корзину
+	Delete(id int32, expectedVersion int32, info models.AuditInfo) (bool, error)
+	// Versions возвращает сохраненные версии баннера, начиная с самой новой
+	Versions(id int32) ([]models.BannerIdVersionsGet200ResponseInner, bool, error)
+	// RestoreVersion делает версию version баннера id текущей
+	RestoreVersion(id int32, version int32, info models.AuditInfo) (bool, error)
+	// Trash возвращает баннеры из корзины, начиная с последних удаленных
+	Trash(limit int32, offset int32) ([]models.BannerTrashGet200ResponseInner, error)
+	// Restore восстанавливает баннер из корзины
+	Restore(id int32, info models.AuditInfo) (bool, error)
+	// Audit возвращает записи журнала изменений, начиная с последних
+	Audit(filter models.AuditFilter) ([]models.AuditGet200ResponseInner, error)
+	// Ping проверяет доступность хранилища
+	Ping() error
+	// Listen передает в handler изменения баннеров, выполненные другими экземплярами сервиса,
+	// а в reset - сигнал о том, что часть изменений могла быть потеряна
+	Listen(handler func(Change), reset func())
+	Stop() error
+}
+
+// Change - изменение баннера, о котором уведомляются все экземпляры сервиса.
+// Features и Tags содержат фичи и тэги баннера до и после изменения
+type Change struct {
+	Id       int32   `json:"id"`
+	Features []int32 `json:"features"`
+	Tags     []int32 `json:"tags"`
+	// Origin - экземпляр сервиса, выполнивший изменение
+	Origin string `json:"origin"`
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{fmt.Errorf("can't find banner: %w", context.DeadlineExceeded), true},
+		{errors.New("can't insert banner: empty slice found"), false},
+		{ErrConflict, false},
+	}
+	for _, tt := range tests {
+		if got := IsConnectionError(tt.err); got != tt.want {
+			t.Errorf("IsConnectionError(%v) = %v; want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,512 @@
+// Package repositorytest содержит общие тесты реализаций repository.Repository,
+// чтобы все хранилища баннеров вели себя одинаково
+package repositorytest
+
+import (
+	"banner/internal/repository"
+	"banner/models"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Factory создает пустое хранилище с заданной конфигурацией
+type Factory func(t *testing.T, cfg repository.Config) repository.Repository
+
+// purger - хранилище, умеющее окончательно удалять баннеры из корзины
+type purger interface {
+	Purge(before time.Time) (int64, error)
+}
+
+// Run запускает общие тесты для хранилища, создаваемого newRepository
+func Run(t *testing.T, newRepository Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, m repository.Repository)
+	}{
+		{"InsertAndGet", testInsertAndGet},
+		{"InsertConflict", testInsertConflict},
+		{"InsertBatch", testInsertBatch},
+		{"Update", testUpdate},
+		{"UpdateWindow", testUpdateWindow},
+		{"Versions", testVersions},
+		{"TrashAndRestore", testTrashAndRestore},
+		{"GetMany", testGetMany},
+		{"GetManyFilters", testGetManyFilters},
+		{"GetManyContent", testGetManyContent},
+		{"GetManyCursor", testGetManyCursor},
+		{"ActiveBanners", testActiveBanners},
+		{"Audit", testAudit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newRepository(t, repository.Config{RevisionsLimit: 3, TrashRetention: time.Hour, PurgeInterval: time.Hour})
+			t.Cleanup(func() {
+				if err := m.Stop(); err != nil {
+					t.Error(err)
+				}
+			})
+			tt.test(t, m)
+		})
+	}
+}
+
+func insert(t *testing.T, m repository.Repository, feature int32, tags ...int32) int32 {
+	t.Helper()
+	id, err := m.Insert(&models.InsertData{
+		Feature:   feature,
+		TagIds:    tags,
+		Content:   models.JSONMap{"title": "banner"},
+		IsActive:  true,
+		AuditInfo: models.AuditInfo{Actor: "admin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func testInsertAndGet(t *testing.T, m repository.Repository) {
+	id := insert(t, m, 1, 2, 3)
+
+	banner, found, err := m.Get(1, 3, true)
+	if err != nil || !found {
+		t.Fatalf("Get(1, 3) = %v, %v; want banner %d", found, err, id)
+	}
+	if banner.BannerId != id || banner.Content["title"] != "banner" || !banner.IsActive || banner.Version != 1 {
+		t.Fatalf("Get(1, 3) = %+v", banner)
+	}
+	if _, found, _ := m.Get(1, 4, true); found {
+		t.Fatal("Get(1, 4) found banner without such tag")
+	}
+
+	// изменение полученного содержимого не затрагивает хранилище
+	banner.Content["title"] = "changed"
+	if banner, _, _ := m.GetById(id); banner.Content["title"] != "banner" {
+		t.Fatalf("stored content changed to %v", banner.Content)
+	}
+}
+
+func testInsertConflict(t *testing.T, m repository.Repository) {
+	insert(t, m, 1, 2)
+
+	if _, err := m.Insert(&models.InsertData{Feature: 1, TagIds: []int32{3, 2}}); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("Insert of used pair returned %v; want ErrConflict", err)
+	}
+	if _, err := m.Insert(&models.InsertData{Feature: 2, TagIds: []int32{5, 5}}); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("Insert with repeated tag returned %v; want ErrConflict", err)
+	}
+	if _, found, _ := m.Get(1, 3, true); found {
+		t.Fatal("failed Insert left pair (1, 3)")
+	}
+	if _, err := m.Insert(&models.InsertData{Feature: 2, TagIds: []int32{2}}); err != nil {
+		t.Fatalf("Insert with another feature: %v", err)
+	}
+}
+
+func testInsertBatch(t *testing.T, m repository.Repository) {
+	insert(t, m, 1, 1)
+	batch := func(pairs ...[2]int32) []*models.InsertData {
+		records := make([]*models.InsertData, 0, len(pairs))
+		for _, p := range pairs {
+			records = append(records, &models.InsertData{Feature: p[0], TagIds: []int32{p[1]}, Content: models.JSONMap{"title": "batch"}})
+		}
+		return records
+	}
+
+	// пара занята существующим баннером или предыдущим баннером пакета: не создается ни один баннер
+	for _, records := range [][]*models.InsertData{batch([2]int32{2, 1}, [2]int32{1, 1}), batch([2]int32{2, 1}, [2]int32{2, 1})} {
+		var batchErr *repository.BatchError
+		if _, err := m.InsertBatch(records); !errors.As(err, &batchErr) || batchErr.Index != 1 || !errors.Is(err, repository.ErrConflict) {
+			t.Fatalf("InsertBatch with used pair returned %v; want ErrConflict for banner 1", err)
+		}
+		if _, found, _ := m.Get(2, 1, true); found {
+			t.Fatal("failed InsertBatch left pair (2, 1)")
+		}
+	}
+
+	ids, err := m.InsertBatch(batch([2]int32{2, 1}, [2]int32{2, 2}, [2]int32{3, 1}))
+	if err != nil || len(ids) != 3 {
+		t.Fatalf("InsertBatch = %v, %v", ids, err)
+	}
+	for i, p := range [][2]int32{{2, 1}, {2, 2}, {3, 1}} {
+		banner, found, err := m.Get(p[0], p[1], true)
+		if err != nil || !found || banner.BannerId != ids[i] || banner.Content["title"] != "batch" {
+			t.Fatalf("Get%v = %+v, %v, %v; want banner %d", p, banner, found, err, ids[i])
+		}
+	}
+}
+
+func testUpdate(t *testing.T, m repository.Repository) {
+	id := insert(t, m, 1, 1, 2)
+	other := insert(t, m, 1, 5)
+
+	if _, _, err := m.Update(id, &models.InsertData{TagIds: []int32{5}}); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("Update to used pair returned %v; want ErrConflict", err)
+	}
+	if _, _, err := m.Update(id, &models.InsertData{ExpectedVersion: 7}); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("Update with wrong version returned %v; want ErrVersionMismatch", err)
+	}
+	// новые тэги заменяют старые по порядку, незаданные поля не изменяются
+	version, found, err := m.Update(id, &models.InsertData{Feature: 2, TagIds: []int32{3}, ExpectedVersion: 1})
+	if err != nil || !found || version != 2 {
+		t.Fatalf("Update = %v, %v, %v", version, found, err)
+	}
+	banner, _, _ := m.GetById(id)
+	if banner.FeatureId != 2 || !reflect.DeepEqual(banner.TagIds, []int32{2, 3}) || banner.Version != 2 || banner.Content["title"] != "banner" {
+		t.Fatalf("banner after Update = %+v", banner)
+	}
+	if _, found, _ := m.Get(1, 1, true); found {
+		t.Fatal("old pair (1, 1) still points to banner")
+	}
+	if got, _, _ := m.Get(1, 5, true); got.BannerId != other {
+		t.Fatal("Update changed another banner")
+	}
+	// явно заданный false снимает флаг активности, незаданный флаг не изменяется
+	if _, _, err := m.Update(other, &models.InsertData{SetIsActive: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := m.GetById(other); got.IsActive {
+		t.Fatal("Update with IsActive false didn't deactivate banner")
+	}
+	if _, _, err := m.Update(other, &models.InsertData{Content: models.JSONMap{"title": "inactive"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := m.GetById(other); got.IsActive {
+		t.Fatal("Update without IsActive activated banner")
+	}
+	if _, found, _ := m.Update(100, &models.InsertData{IsActive: true}); found {
+		t.Fatal("Update found missing banner")
+	}
+}
+
+func testUpdateWindow(t *testing.T, m repository.Repository) {
+	startsAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	endsAt := startsAt.Add(time.Hour)
+	earlier := startsAt.Add(-time.Minute)
+	id, err := m.Insert(&models.InsertData{Feature: 1, TagIds: []int32{1}, IsActive: true, StartsAt: &startsAt, EndsAt: &endsAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// окно проверяется вместе с сохраненной границей
+	if _, _, err := m.Update(id, &models.InsertData{EndsAt: &earlier}); !errors.Is(err, repository.ErrInvalidWindow) {
+		t.Fatalf("Update with ends_at before stored starts_at returned %v; want ErrInvalidWindow", err)
+	}
+	later := endsAt.Add(time.Minute)
+	if _, _, err := m.Update(id, &models.InsertData{StartsAt: &later}); !errors.Is(err, repository.ErrInvalidWindow) {
+		t.Fatalf("Update with starts_at after stored ends_at returned %v; want ErrInvalidWindow", err)
+	}
+	if banner, _, _ := m.GetById(id); banner.Version != 1 {
+		t.Fatalf("rejected Update changed banner: %+v", banner)
+	}
+
+	// сброшенная граница не ограничивает окно
+	if _, _, err := m.Update(id, &models.InsertData{EndsAt: &earlier, ClearStartsAt: true}); err != nil {
+		t.Fatal(err)
+	}
+	banner, _, _ := m.GetById(id)
+	if banner.StartsAt != nil || banner.EndsAt == nil || !banner.EndsAt.Equal(earlier) || !banner.IsActive {
+		t.Fatalf("banner after clearing starts_at = %+v", banner)
+	}
+	if _, _, err := m.Update(id, &models.InsertData{ClearEndsAt: true}); err != nil {
+		t.Fatal(err)
+	}
+	if banner, _, _ := m.GetById(id); banner.StartsAt != nil || banner.EndsAt != nil {
+		t.Fatalf("banner after clearing ends_at = %+v", banner)
+	}
+}
+
+func testVersions(t *testing.T, m repository.Repository) {
+	id := insert(t, m, 1, 1)
+	for _, title := range []string{"a", "b", "c"} {
+		if _, _, err := m.Update(id, &models.InsertData{Content: models.JSONMap{"title": title}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versions, found, _ := m.Versions(id)
+	if !found || len(versions) != 3 || versions[0].Version != 4 || versions[2].Version != 2 {
+		t.Fatalf("Versions = %+v; want 3 latest versions", versions)
+	}
+
+	found, err := m.RestoreVersion(id, 2, models.AuditInfo{Actor: "admin"})
+	if err != nil || !found {
+		t.Fatalf("RestoreVersion = %v, %v", found, err)
+	}
+	if banner, _, _ := m.GetById(id); banner.Content["title"] != "a" || banner.Version != 5 {
+		t.Fatalf("banner after RestoreVersion = %+v", banner)
+	}
+	if found, _ := m.RestoreVersion(id, 1, models.AuditInfo{}); found {
+		t.Fatal("RestoreVersion restored version over REVISIONS_LIMIT")
+	}
+}
+
+func testTrashAndRestore(t *testing.T, m repository.Repository) {
+	id := insert(t, m, 1, 1, 2)
+
+	if _, err := m.Delete(id, 5, models.AuditInfo{}); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("Delete with wrong version returned %v; want ErrVersionMismatch", err)
+	}
+	if found, err := m.Delete(id, 0, models.AuditInfo{}); err != nil || !found {
+		t.Fatalf("Delete = %v, %v", found, err)
+	}
+	if _, found, _ := m.GetById(id); found {
+		t.Fatal("deleted banner is returned by GetById")
+	}
+	trash, _ := m.Trash(0, 0)
+	if len(trash) != 1 || trash[0].BannerId != id || trash[0].FeatureId != 1 || !reflect.DeepEqual(trash[0].TagIds, []int32{1, 2}) {
+		t.Fatalf("Trash = %+v", trash)
+	}
+
+	other := insert(t, m, 1, 2)
+	if _, err := m.Restore(id, models.AuditInfo{}); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("Restore over used pair returned %v; want ErrConflict", err)
+	}
+	if _, err := m.Delete(other, 0, models.AuditInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := m.Restore(id, models.AuditInfo{}); err != nil || !found {
+		t.Fatalf("Restore = %v, %v", found, err)
+	}
+	if banner, found, _ := m.Get(1, 2, true); !found || banner.BannerId != id || banner.Version != 2 {
+		t.Fatalf("Get(1, 2) after Restore = %+v, %v", banner, found)
+	}
+	// восстановление сохраняется как новая версия с номером, равным версии баннера
+	if versions, _, err := m.Versions(id); err != nil || len(versions) == 0 || versions[0].Version != 2 {
+		t.Fatalf("Versions after Restore = %+v, %v; want latest version 2", versions, err)
+	}
+
+	p, ok := m.(purger)
+	if !ok {
+		t.Fatalf("%T doesn't implement Purge", m)
+	}
+	if purged, err := p.Purge(time.Now()); err != nil || purged != 1 {
+		t.Fatalf("Purge = %d, %v; want 1", purged, err)
+	}
+	if found, _ := m.Restore(other, models.AuditInfo{}); found {
+		t.Fatal("purged banner restored")
+	}
+}
+
+// bannerIds возвращает идентификаторы баннеров из ответа GetMany
+func bannerIds(banners []map[string]interface{}) []int32 {
+	res := make([]int32, 0, len(banners))
+	for _, b := range banners {
+		res = append(res, b["banner_id"].(int32))
+	}
+	return res
+}
+
+// checkGetMany проверяет, что GetMany(filter) возвращает баннеры want из total подходящих
+func checkGetMany(t *testing.T, m repository.Repository, filter models.BannerFilter, want []int32, total int64) {
+	t.Helper()
+	banners, gotTotal, err := m.GetMany(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bannerIds(banners); !reflect.DeepEqual(got, want) || gotTotal != total {
+		t.Fatalf("GetMany(%+v) = %v, %d; want %v, %d", filter, got, gotTotal, want, total)
+	}
+}
+
+func testGetMany(t *testing.T, m repository.Repository) {
+	first := insert(t, m, 1, 1)
+	second := insert(t, m, 1, 2)
+	third := insert(t, m, 2, 1)
+
+	cases := []struct {
+		filter models.BannerFilter
+		want   []int32
+		total  int64
+	}{
+		{models.BannerFilter{}, []int32{first, second, third}, 3},
+		{models.BannerFilter{FeatureIds: []int32{1}}, []int32{first, second}, 2},
+		{models.BannerFilter{TagIds: []int32{1}}, []int32{first, third}, 2},
+		{models.BannerFilter{FeatureIds: []int32{1}, TagIds: []int32{1}}, []int32{first}, 1},
+		{models.BannerFilter{FeatureIds: []int32{1}, Limit: 1, Offset: 1}, []int32{second}, 2},
+		{models.BannerFilter{FeatureIds: []int32{3}}, []int32{}, 0},
+	}
+	for _, c := range cases {
+		checkGetMany(t, m, c.filter, c.want, c.total)
+	}
+	if ids, _ := m.FindIds(0, 1); !reflect.DeepEqual(ids, []int32{first, third}) {
+		t.Fatalf("FindIds(0, 1) = %v", ids)
+	}
+}
+
+func testGetManyFilters(t *testing.T, m repository.Repository) {
+	first := insert(t, m, 1, 1, 2)
+	second := insert(t, m, 2, 3)
+	third := insert(t, m, 3, 1)
+	if _, err := m.Insert(&models.InsertData{Feature: 4, TagIds: []int32{4}}); err != nil {
+		t.Fatal(err)
+	}
+	inactive := int32(4)
+	if banners, _, _ := m.GetMany(models.BannerFilter{FeatureIds: []int32{4}}); len(banners) == 1 {
+		inactive = banners[0]["banner_id"].(int32)
+	}
+	time.Sleep(10 * time.Millisecond)
+	// изменение переносит баннер в конец порядка по updated_at, но не по created_at
+	if _, _, err := m.Update(first, &models.InsertData{Content: models.JSONMap{"title": "changed"}}); err != nil {
+		t.Fatal(err)
+	}
+	created, _, _ := m.GetById(third)
+	updated, _, _ := m.GetById(first)
+	active, notActive := true, false
+
+	cases := []struct {
+		filter models.BannerFilter
+		want   []int32
+		total  int64
+	}{
+		{models.BannerFilter{FeatureIds: []int32{1, 3}}, []int32{first, third}, 2},
+		{models.BannerFilter{TagIds: []int32{2, 3}}, []int32{first, second}, 2},
+		{models.BannerFilter{FeatureIds: []int32{1, 2}, TagIds: []int32{1, 4}}, []int32{first}, 1},
+		{models.BannerFilter{IsActive: &active}, []int32{first, second, third}, 3},
+		{models.BannerFilter{IsActive: &notActive}, []int32{inactive}, 1},
+		{models.BannerFilter{CreatedFrom: created.CreatedAt}, []int32{third, inactive}, 2},
+		{models.BannerFilter{CreatedTo: created.CreatedAt, Limit: 2}, []int32{first, second}, 3},
+		{models.BannerFilter{UpdatedFrom: updated.UpdatedAt}, []int32{first}, 1},
+		{models.BannerFilter{UpdatedTo: updated.UpdatedAt.Add(-time.Millisecond)}, []int32{second, third, inactive}, 3},
+		{models.BannerFilter{Sort: models.BannerSort{Field: models.SortById, Desc: true}}, []int32{inactive, third, second, first}, 4},
+		{models.BannerFilter{Sort: models.BannerSort{Field: models.SortByCreatedAt, Desc: true}, Limit: 2}, []int32{inactive, third}, 4},
+		{models.BannerFilter{Sort: models.BannerSort{Field: models.SortByUpdatedAt}}, []int32{second, third, inactive, first}, 4},
+		{models.BannerFilter{Sort: models.BannerSort{Field: models.SortByUpdatedAt, Desc: true}, Offset: 1}, []int32{inactive, third, second}, 4},
+	}
+	for _, c := range cases {
+		checkGetMany(t, m, c.filter, c.want, c.total)
+	}
+}
+
+func testGetManyContent(t *testing.T, m repository.Repository) {
+	create := func(feature int32, content models.JSONMap) int32 {
+		id, err := m.Insert(&models.InsertData{Feature: feature, TagIds: []int32{1}, Content: content, IsActive: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	sale := create(1, models.JSONMap{"title": "Sale", "priority": 5, "meta": map[string]interface{}{"lang": "ru", "tags": []interface{}{"a", "b"}}})
+	news := create(2, models.JSONMap{"title": "News", "priority": "5"})
+	other := create(3, models.JSONMap{"title": "Sale", "meta": map[string]interface{}{"lang": "en"}})
+
+	cases := []struct {
+		filter models.BannerFilter
+		want   []int32
+		total  int64
+	}{
+		{models.BannerFilter{ContentFields: map[string]string{"title": "Sale"}}, []int32{sale, other}, 2},
+		{models.BannerFilter{ContentFields: map[string]string{"title": "sale"}}, []int32{}, 0},
+		{models.BannerFilter{ContentFields: map[string]string{"priority": "5"}}, []int32{sale, news}, 2},
+		{models.BannerFilter{ContentFields: map[string]string{"title": "Sale", "meta.lang": "ru"}}, []int32{sale}, 1},
+		{models.BannerFilter{ContentFields: map[string]string{"title": "Sale"}, FeatureIds: []int32{3}}, []int32{other}, 1},
+		{models.BannerFilter{ContentFields: map[string]string{"title": "Sale"}, Limit: 1, Offset: 1}, []int32{other}, 2},
+		{models.BannerFilter{ContentFields: map[string]string{"title": "Sale"}, Sort: models.BannerSort{Field: models.SortById, Desc: true}}, []int32{other, sale}, 2},
+		{models.BannerFilter{ContentContains: models.JSONMap{"meta": map[string]interface{}{"tags": []interface{}{"b"}}}}, []int32{sale}, 1},
+		{models.BannerFilter{ContentContains: models.JSONMap{"meta": map[string]interface{}{}}}, []int32{sale, other}, 2},
+		{models.BannerFilter{ContentContains: models.JSONMap{"title": "Sale"}, TagIds: []int32{2}}, []int32{}, 0},
+	}
+	for _, c := range cases {
+		checkGetMany(t, m, c.filter, c.want, c.total)
+	}
+
+	if _, err := m.Delete(other, 0, models.AuditInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	checkGetMany(t, m, models.BannerFilter{ContentFields: map[string]string{"title": "Sale"}}, []int32{sale}, 1)
+}
+
+func testGetManyCursor(t *testing.T, m repository.Repository) {
+	ids := make([]int32, 0)
+	for feature := int32(1); feature <= 5; feature++ {
+		ids = append(ids, insert(t, m, feature, 1))
+	}
+	// измененный баннер перемещается в конец списка
+	if _, _, err := m.Update(ids[1], &models.InsertData{Content: models.JSONMap{"title": "changed"}}); err != nil {
+		t.Fatal(err)
+	}
+	byUpdated := []int32{ids[0], ids[2], ids[3], ids[4], ids[1]}
+
+	cases := []struct {
+		sort    models.BannerSort
+		content map[string]string
+		want    []int32
+	}{
+		{models.BannerSort{Field: models.SortByUpdatedAt}, nil, byUpdated},
+		{models.BannerSort{Field: models.SortByUpdatedAt, Desc: true}, nil, []int32{ids[1], ids[4], ids[3], ids[2], ids[0]}},
+		{models.BannerSort{Field: models.SortById, Desc: true}, nil, []int32{ids[4], ids[3], ids[2], ids[1], ids[0]}},
+		{models.BannerSort{Field: models.SortByCreatedAt}, map[string]string{"title": "banner"}, []int32{ids[0], ids[2], ids[3], ids[4]}},
+	}
+	for _, c := range cases {
+		got := make([]int32, 0)
+		cursor := models.BannerCursor{}
+		for page := 0; page < 4; page++ {
+			filter := models.BannerFilter{TagIds: []int32{1}, ContentFields: c.content, Sort: c.sort, After: &cursor, Limit: 2}
+			banners, total, err := m.GetMany(filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != int64(len(c.want)) {
+				t.Fatalf("GetMany(%+v) total = %d; want %d", filter, total, len(c.want))
+			}
+			got = append(got, bannerIds(banners)...)
+			if len(banners) == 0 {
+				break
+			}
+			// курсор передается клиенту строкой
+			next := models.BannerCursor{Sort: c.sort.String(), BannerKey: c.sort.Key(banners[len(banners)-1])}
+			if cursor, err = models.ParseBannerCursor(next.String()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("pages by %s = %v; want %v", c.sort, got, c.want)
+		}
+	}
+}
+
+func testActiveBanners(t *testing.T, m repository.Repository) {
+	past := time.Now().Add(-time.Hour)
+	first := insert(t, m, 1, 1)
+	if _, err := m.Insert(&models.InsertData{Feature: 1, TagIds: []int32{2}, IsActive: true, EndsAt: &past}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Insert(&models.InsertData{Feature: 1, TagIds: []int32{3}}); err != nil {
+		t.Fatal(err)
+	}
+	last := insert(t, m, 1, 4)
+
+	banners, err := m.ActiveBanners(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(banners) != 2 || banners[0].BannerId != last || banners[1].BannerId != first {
+		t.Fatalf("ActiveBanners = %+v; want banners %d, %d", banners, last, first)
+	}
+}
+
+func testAudit(t *testing.T, m repository.Repository) {
+	id := insert(t, m, 1, 1)
+	if _, _, err := m.Update(id, &models.InsertData{Content: models.JSONMap{"title": "new"}, AuditInfo: models.AuditInfo{Actor: "editor"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Delete(id, 0, models.AuditInfo{Actor: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, _ := m.Audit(models.AuditFilter{BannerId: id})
+	if len(records) != 3 || records[0].Action != repository.ActionDelete || records[2].Action != repository.ActionCreate {
+		t.Fatalf("Audit = %+v", records)
+	}
+	if records[0].After != nil || records[2].Before != nil {
+		t.Fatal("create and delete records must have empty before and after states")
+	}
+	if diff, ok := records[1].Diff["content"]; !ok || len(records[1].Diff) != 1 {
+		t.Fatalf("update diff = %v; want only content", diff)
+	}
+	if records, _ := m.Audit(models.AuditFilter{Actor: "admin", Limit: 1, Offset: 1}); len(records) != 1 || records[0].Action != repository.ActionCreate {
+		t.Fatalf("Audit(actor=admin, offset=1) = %+v", records)
+	}
+}
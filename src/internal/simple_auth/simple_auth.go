@@ -11,3 +11,14 @@ func CheckAdminToken(token string) (bool, error) {
 	}
 	return false, errors.New("invalid token")
 }
+
+// GetActor возвращает имя пользователя, которому принадлежит токен
+func GetActor(token string) string {
+	switch token {
+	case "admin_token":
+		return "admin"
+	case "user_token":
+		return "user"
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// Package sqlite - хранилище баннеров в файле SQLite для небольших установок и локальной разработки
+package sqlite
+
+import (
+	"banner/internal/gormstore"
+	"banner/internal/repository"
+	"banner/models"
+	"errors"
+	"os"
+
+	gormsqlite "github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const defaultSQLitePath = "./banners.db"
+
+// sqliteOptions: ожидание блокировки вместо ошибки SQLITE_BUSY, журнал WAL (чтение не блокируется записью)
+// и захват блокировки записи в начале транзакции, чтобы параллельные транзакции не прерывали друг друга
+const sqliteOptions = "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+// SQLite - хранилище баннеров в SQLite. Модели и запросы общие с Postgres (см. gormstore). Базу использует
+// один экземпляр сервиса, поэтому чтение идет с единственной базы, а уведомления об изменениях не отправляются
+type SQLite struct {
+	*gormstore.Store
+}
+
+// NewSQLiteRepository открывает базу SQLite в файле SQLITE_PATH (по умолчанию ./banners.db)
+func NewSQLiteRepository() *SQLite {
+	path := os.Getenv("SQLITE_PATH")
+	if path == "" {
+		path = defaultSQLitePath
+	}
+	s, err := Open(path, repository.LoadConfig())
+	if err != nil {
+		panic("couldn't open sqlite database: " + err.Error())
+	}
+	s.StartPurger()
+	return s
+}
+
+// Open открывает базу SQLite в файле path. У SQLite нет SQL-миграций Postgres, поэтому схема создается
+// и обновляется по моделям gormstore при каждом открытии
+func Open(path string, cfg repository.Config) (*SQLite, error) {
+	db, err := gorm.Open(gormsqlite.Open("file:"+path+sqliteOptions), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tables := []interface{}{&gormstore.Banner{}, &gormstore.Data{}, &gormstore.Revision{}, &gormstore.AuditRecord{}}
+	if err := useSQLiteTimeColumns(db, tables...); err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		return nil, errors.New("can't migrate databases: " + err.Error())
+	}
+	if err := migrateSQLiteAuditTrigger(db); err != nil {
+		return nil, err
+	}
+	s := &SQLite{}
+	s.Store = gormstore.New(db, s, cfg)
+	return s, nil
+}
+
+// Read выполняет чтение на единственной базе
+func (s *SQLite) Read(fc func(db *gorm.DB) error) error {
+	return fc(s.Db)
+}
+
+// Notify ничего не делает: других экземпляров сервиса, которых нужно уведомить, нет
+func (s *SQLite) Notify(tx *gorm.DB, id int32, before, after *gormstore.Revision) error {
+	return nil
+}
+
+// WhereContent не изменяет запрос: SQLite не поддерживает оператор @>, и условия на содержимое
+// проверяются после выборки
+func (s *SQLite) WhereContent(query *gorm.DB, filter models.BannerFilter) (*gorm.DB, bool) {
+	return query, false
+}
+
+// Listen ничего не делает: база не меняется другими экземплярами сервиса
+func (s *SQLite) Listen(handler func(repository.Change), reset func()) {
+}
+
+// useSQLiteTimeColumns заменяет тип timestamptz на datetime в схемах моделей: драйвер SQLite преобразует
+// в time.Time только столбцы с типами DATE, DATETIME и TIMESTAMP. Схемы кэшируются отдельно для каждого
+// подключения gorm, поэтому замена не затрагивает подключения к Postgres
+func useSQLiteTimeColumns(db *gorm.DB, tables ...interface{}) error {
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(table); err != nil {
+			return errors.New("can't parse model: " + err.Error())
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DataType == "timestamptz" {
+				field.DataType = "datetime"
+			}
+		}
+	}
+	return nil
+}
+
+// migrateSQLiteAuditTrigger создает триггеры, запрещающие изменение и удаление записей журнала
+func migrateSQLiteAuditTrigger(db *gorm.DB) error {
+	for _, event := range []string{"UPDATE", "DELETE"} {
+		err := db.Exec(`CREATE TRIGGER IF NOT EXISTS audit_log_append_only_` + event + ` BEFORE ` + event + ` ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END`).Error
+		if err != nil {
+			return errors.New("can't create audit trigger: " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package sqlite
+
+import (
+	"banner/internal/repository"
+	"banner/internal/repository/repositorytest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLite(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, cfg repository.Config) repository.Repository {
+		s, err := Open(filepath.Join(t.TempDir(), "banners.db"), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
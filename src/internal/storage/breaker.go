@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"banner/internal/breaker"
+	"banner/internal/repository"
+	"banner/models"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrUnavailable возвращается вместо обращения к базе данных, пока предохранитель разомкнут
+var ErrUnavailable = breaker.ErrOpen
+
+const (
+	defaultBreakerThreshold     = 5
+	defaultBreakerProbeInterval = 5 * time.Second
+)
+
+// newBreaker создает предохранитель для базы данных: после DB_BREAKER_THRESHOLD ошибок подряд
+// запросы к базе прекращаются, а ее доступность проверяется раз в DB_BREAKER_PROBE_INTERVAL
+func newBreaker(probe func() error) *breaker.Breaker {
+	threshold := defaultBreakerThreshold
+	if value := os.Getenv("DB_BREAKER_THRESHOLD"); value != "" {
+		var err error
+		threshold, err = strconv.Atoi(value)
+		if err != nil || threshold <= 0 {
+			panic("Can't parse DB_BREAKER_THRESHOLD: " + value)
+		}
+	}
+	interval := defaultBreakerProbeInterval
+	if value := os.Getenv("DB_BREAKER_PROBE_INTERVAL"); value != "" {
+		var err error
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			panic("Can't parse DB_BREAKER_PROBE_INTERVAL: " + value)
+		}
+	}
+	return breaker.New(threshold, interval, probe)
+}
+
+// unavailable заменяет ошибку соединения с базой на ErrUnavailable, чтобы обработчик ответил 503,
+// а не выдал текст ошибки драйвера
+func unavailable(err error) error {
+	if repository.IsConnectionError(err) {
+		return ErrUnavailable
+	}
+	return err
+}
+
+// observe учитывает результат обращения к базе в предохранителе. Ошибка соединения считается неудачей сразу.
+// Остальные ошибки могут быть вызваны самим запросом (например, пустым списком тэгов), поэтому для них база
+// проверяется ping в фоне, не задерживая запрос: неудачей считается только ошибка, после которой база не отвечает
+func (s *Storage) observe(err error) {
+	switch {
+	case err == nil || errors.Is(err, ErrVersionMismatch) || errors.Is(err, ErrConflict) || errors.Is(err, ErrInvalidWindow):
+		s.breaker.Success()
+	case repository.IsConnectionError(err):
+		s.breaker.Failure()
+	default:
+		s.pingInBackground()
+	}
+}
+
+// pingInBackground проверяет базу в фоне. Одновременно выполняется не больше одной проверки
+func (s *Storage) pingInBackground() {
+	if !s.pinging.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.pinging.Store(false)
+		if s.db.Ping() != nil {
+			s.breaker.Failure()
+		}
+	}()
+}
+
+// lastKnown возвращает последнее известное содержимое баннера из кэша (в том числе устаревшее),
+// когда база данных недоступна
+func (s *Storage) lastKnown(feature, tag int32) (models.JSONMap, bool, bool, error) {
+	content, userAccess := s.cache.GetLastKnown(feature, tag)
+	if content == nil {
+		return nil, false, false, ErrUnavailable
+	}
+	s.lastKnownHits.Add(1)
+	return content, userAccess, true, nil
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"banner/internal/breaker"
+	"banner/internal/cashe"
+	"banner/internal/repository"
+	"banner/models"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// downRepository - хранилище, чтение из которого завершается ошибкой err. Ping ждет release
+type downRepository struct {
+	repository.Repository
+	err     error
+	release chan struct{}
+	pings   chan struct{}
+}
+
+func (r *downRepository) Get(feature, tag int32, latest bool) (models.BannerGet200ResponseInner, bool, error) {
+	return models.BannerGet200ResponseInner{}, false, r.err
+}
+
+func (r *downRepository) Ping() error {
+	<-r.release
+	r.pings <- struct{}{}
+	return errors.New("database is down")
+}
+
+func newDownStorage(t *testing.T, err error) (*Storage, *downRepository) {
+	t.Setenv("CACHE_CLEANUP_INTERVAL", "1h")
+	db := &downRepository{err: err, release: make(chan struct{}), pings: make(chan struct{}, 1)}
+	s := &Storage{
+		db:       db,
+		cache:    cashe.NewMemoryCache(time.Minute, time.Hour),
+		negative: cashe.NewNegativeCache(),
+		loads:    newLoadGroup(),
+		breaker:  breaker.New(1, time.Hour, func() error { return errors.New("database is down") }),
+	}
+	t.Cleanup(s.breaker.Stop)
+	s.cache.AddOne(cashe.Item{BannerID: 1, FeatureID: 1, TagIDs: []int32{1}, IsActive: true, Content: models.JSONMap{"title": "cached"}})
+	return s, db
+}
+
+func TestGetUserBannerLatestUnavailable(t *testing.T) {
+	s, _ := newDownStorage(t, fmt.Errorf("can't read banner: %w", &net.OpError{Op: "read", Err: errors.New("connection reset")}))
+
+	// последняя версия не подменяется кэшем ни при ошибке базы, ни при разомкнутом предохранителе
+	for i := 0; i < 2; i++ {
+		if _, _, _, err := s.GetUserBanner(context.Background(), 1, 1, true); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("GetUserBanner(latest) returned %v; want ErrUnavailable", err)
+		}
+	}
+	if s.breaker.Allow() {
+		t.Fatal("connection error did not open breaker")
+	}
+	if content, _, found, err := s.GetUserBanner(context.Background(), 1, 1, false); err != nil || !found || content["title"] != "cached" {
+		t.Fatalf("GetUserBanner = %v, %v, %v; want last known banner", content, found, err)
+	}
+}
+
+func TestGetUserBannerLatestQueryError(t *testing.T) {
+	queryErr := errors.New("can't read banner: invalid character in content")
+	s, _ := newDownStorage(t, queryErr)
+
+	// ошибка запроса - не недоступность базы
+	if _, _, _, err := s.GetUserBanner(context.Background(), 1, 1, true); !errors.Is(err, queryErr) {
+		t.Fatalf("GetUserBanner(latest) returned %v; want query error", err)
+	}
+}
+
+func TestGetManyUnavailable(t *testing.T) {
+	s, _ := newDownStorage(t, nil)
+	s.breaker.Failure()
+	if _, _, err := s.GetMany(models.BannerFilter{}); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("GetMany with open breaker returned %v; want ErrUnavailable", err)
+	}
+	reads := map[string]func() error{
+		"GetById":  func() error { _, _, err := s.GetById(1); return err },
+		"Versions": func() error { _, _, err := s.Versions(1); return err },
+		"Trash":    func() error { _, err := s.Trash(0, 0); return err },
+		"Audit":    func() error { _, err := s.Audit(models.AuditFilter{}); return err },
+	}
+	for name, read := range reads {
+		if err := read(); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("%s with open breaker returned %v; want ErrUnavailable", name, err)
+		}
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	connErr := fmt.Errorf("can't read banner: %w", &net.OpError{Op: "read", Err: errors.New("connection reset")})
+	if err := unavailable(connErr); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("unavailable(connection error) = %v; want ErrUnavailable", err)
+	}
+	queryErr := errors.New("can't get versions: syntax error")
+	if err := unavailable(queryErr); err != queryErr {
+		t.Fatalf("unavailable(query error) = %v; want query error", err)
+	}
+}
+
+func TestObservePingsInBackground(t *testing.T) {
+	s, db := newDownStorage(t, nil)
+
+	// ошибка запроса не ждет ping и не размыкает предохранитель, пока база не ответит
+	s.observe(errors.New("can't insert banner: empty slice found"))
+	s.observe(errors.New("can't insert banner: empty slice found"))
+	if !s.breaker.Allow() {
+		t.Fatal("breaker opened before ping")
+	}
+	close(db.release)
+	<-db.pings
+	deadline := time.Now().Add(time.Second)
+	for s.breaker.Allow() {
+		if time.Now().After(deadline) {
+			t.Fatal("failed ping did not open breaker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-db.pings:
+		t.Fatal("concurrent errors started more than one ping")
+	default:
+	}
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"banner/internal/cashe"
+	"banner/models"
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// userBanner - результат загрузки баннера для пары фича-тэг
+type userBanner struct {
+	content    models.JSONMap
+	userAccess bool
+	found      bool
+}
+
+type loadCall struct {
+	done   chan struct{}
+	result userBanner
+	err    error
+}
+
+// loadGroup объединяет одновременные загрузки баннера для одной пары фича-тэг в одну:
+// остальные запросы ждут результата первой загрузки
+type loadGroup struct {
+	sync.Mutex
+	calls map[cashe.Key]*loadCall
+	// deduplicated - количество запросов, которые дождались чужой загрузки вместо своей
+	deduplicated atomic.Int64
+}
+
+func newLoadGroup() *loadGroup {
+	return &loadGroup{calls: make(map[cashe.Key]*loadCall)}
+}
+
+// do выполняет load или присоединяется к уже выполняющейся загрузке для key.
+// Загрузка выполняется в отдельной горутине, поэтому отмена ctx прерывает ожидание
+// только для этого запроса, остальные получат результат
+func (g *loadGroup) do(ctx context.Context, key cashe.Key, load func() (userBanner, error)) (userBanner, error) {
+	g.Lock()
+	call, ok := g.calls[key]
+	if ok {
+		g.deduplicated.Add(1)
+	} else {
+		call = g.start(key, load)
+	}
+	g.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return userBanner{}, ctx.Err()
+	}
+}
+
+// refresh запускает загрузку для key в фоне, если она еще не выполняется, и не ждет ее результата
+func (g *loadGroup) refresh(key cashe.Key, load func() (userBanner, error)) bool {
+	g.Lock()
+	defer g.Unlock()
+	if _, ok := g.calls[key]; ok {
+		return false
+	}
+	g.start(key, load)
+	return true
+}
+
+// start запускает загрузку в отдельной горутине. Вызывается под блокировкой
+func (g *loadGroup) start(key cashe.Key, load func() (userBanner, error)) *loadCall {
+	call := &loadCall{done: make(chan struct{})}
+	g.calls[key] = call
+	go func() {
+		call.result, call.err = load()
+		g.Lock()
+		delete(g.calls, key)
+		g.Unlock()
+		close(call.done)
+	}()
+	return call
+}
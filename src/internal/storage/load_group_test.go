@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"banner/internal/cashe"
+	"banner/models"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadGroupDeduplicates(t *testing.T) {
+	g := newLoadGroup()
+	key := cashe.Key{Feature: 1, Tag: 1}
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func() (userBanner, error) {
+		loads.Add(1)
+		<-release
+		return userBanner{content: models.JSONMap{"title": "a"}, found: true}, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan userBanner, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := g.do(context.Background(), key, load)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- res
+		}()
+	}
+	for g.deduplicated.Load() != callers-1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("load called %d times; want 1", n)
+	}
+	for res := range results {
+		if !res.found || res.content["title"] != "a" {
+			t.Fatalf("waiter got %+v; want shared result", res)
+		}
+	}
+	if len(g.calls) != 0 {
+		t.Fatalf("%d calls left after load", len(g.calls))
+	}
+}
+
+func TestLoadGroupRefresh(t *testing.T) {
+	g := newLoadGroup()
+	key := cashe.Key{Feature: 1, Tag: 1}
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func() (userBanner, error) {
+		loads.Add(1)
+		<-release
+		return userBanner{found: true}, nil
+	}
+
+	if !g.refresh(key, load) {
+		t.Fatal("refresh did not start a load")
+	}
+	if g.refresh(key, load) {
+		t.Fatal("refresh started a second load for the same pair")
+	}
+	waiter := make(chan userBanner)
+	go func() {
+		res, _ := g.do(context.Background(), key, load)
+		waiter <- res
+	}()
+	for g.deduplicated.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if res := <-waiter; !res.found {
+		t.Fatal("caller did not get the result of the background refresh")
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("load called %d times; want 1", n)
+	}
+}
+
+func TestLoadGroupCancellation(t *testing.T) {
+	g := newLoadGroup()
+	key := cashe.Key{Feature: 1, Tag: 1}
+	release := make(chan struct{})
+	load := func() (userBanner, error) {
+		<-release
+		return userBanner{found: true}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := g.do(ctx, key, load)
+		canceled <- err
+	}()
+	for {
+		g.Lock()
+		started := len(g.calls) == 1
+		g.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	waiter := make(chan userBanner)
+	go func() {
+		res, _ := g.do(context.Background(), key, load)
+		waiter <- res
+	}()
+	// ждем, пока второй запрос присоединится к загрузке первого
+	for g.deduplicated.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller got %v; want context.Canceled", err)
+	}
+	close(release)
+	if res := <-waiter; !res.found {
+		t.Fatal("cancellation of one caller broke the load for others")
+	}
+}
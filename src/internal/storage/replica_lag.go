@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"os"
+	"time"
+)
+
+const defaultReplicaLag = 5 * time.Second
+
+// loadReplicaLag возвращает наибольшее ожидаемое отставание реплик от основной базы из POSTGRES_REPLICA_MAX_LAG
+func loadReplicaLag() time.Duration {
+	value := os.Getenv("POSTGRES_REPLICA_MAX_LAG")
+	if value == "" {
+		return defaultReplicaLag
+	}
+	lag, err := time.ParseDuration(value)
+	if err != nil || lag < 0 {
+		panic("Can't parse POSTGRES_REPLICA_MAX_LAG: " + value)
+	}
+	return lag
+}
+
+// markWritten отмечает изменение баннеров этим или другим экземпляром сервиса. Вызывается до удаления
+// баннеров из кэша: загрузка, начатая после удаления, уже читает основную базу
+func (s *Storage) markWritten() {
+	s.lastWrite.Store(time.Now().UnixNano())
+}
+
+// recentlyWritten сообщает, что реплики могут еще не содержать последнее изменение баннеров
+func (s *Storage) recentlyWritten() bool {
+	return time.Since(time.Unix(0, s.lastWrite.Load())) < s.replicaLag
+}
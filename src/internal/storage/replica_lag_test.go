@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"banner/internal/breaker"
+	"banner/internal/cashe"
+	"banner/internal/repository"
+	"banner/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// laggingRepository - хранилище с отстающей репликой: изменения видны только при чтении с основной базы (latest)
+type laggingRepository struct {
+	repository.Repository
+	primary map[int32]models.JSONMap
+	replica map[int32]models.JSONMap
+}
+
+func (r *laggingRepository) Get(feature, tag int32, latest bool) (models.BannerGet200ResponseInner, bool, error) {
+	banners := r.replica
+	if latest {
+		banners = r.primary
+	}
+	content, found := banners[tag]
+	if !found {
+		return models.BannerGet200ResponseInner{}, false, nil
+	}
+	return models.BannerGet200ResponseInner{BannerId: tag, FeatureId: feature, TagIds: []int32{tag}, Content: content, IsActive: true}, true, nil
+}
+
+func (r *laggingRepository) GetById(id int32) (models.BannerGet200ResponseInner, bool, error) {
+	content, found := r.primary[id]
+	return models.BannerGet200ResponseInner{BannerId: id, FeatureId: 1, TagIds: []int32{id}, Content: content, IsActive: true}, found, nil
+}
+
+func (r *laggingRepository) Update(id int32, newValue *models.InsertData) (int32, bool, error) {
+	r.primary[id] = newValue.Content
+	return 2, true, nil
+}
+
+func (r *laggingRepository) Delete(id int32, expectedVersion int32, info models.AuditInfo) (bool, error) {
+	delete(r.primary, id)
+	return true, nil
+}
+
+func newLaggingStorage(t *testing.T, lag time.Duration) (*Storage, *laggingRepository) {
+	t.Setenv("CACHE_CLEANUP_INTERVAL", "1h")
+	db := &laggingRepository{
+		primary: map[int32]models.JSONMap{1: {"title": "old"}, 2: {"title": "old"}},
+		replica: map[int32]models.JSONMap{1: {"title": "old"}, 2: {"title": "old"}},
+	}
+	s := &Storage{
+		db:         db,
+		cache:      cashe.NewMemoryCache(time.Minute, time.Hour),
+		negative:   cashe.NewNegativeCache(),
+		loads:      newLoadGroup(),
+		breaker:    breaker.New(1, time.Hour, func() error { return nil }),
+		replicaLag: lag,
+	}
+	t.Cleanup(s.breaker.Stop)
+	return s, db
+}
+
+func TestRefillAfterWriteReadsPrimary(t *testing.T) {
+	s, _ := newLaggingStorage(t, time.Minute)
+	for tag := int32(1); tag <= 2; tag++ {
+		if content, _, _, err := s.GetUserBanner(context.Background(), 1, tag, false); err != nil || content["title"] != "old" {
+			t.Fatalf("GetUserBanner(%d) = %v, %v; want old banner", tag, content, err)
+		}
+	}
+
+	if _, _, err := s.Update(1, &models.InsertData{Content: models.JSONMap{"title": "new"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Delete(2, 0, models.AuditInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// реплика еще не получила изменения, но промах кэша после записи читает основную базу
+	for i := 0; i < 2; i++ {
+		if content, _, _, err := s.GetUserBanner(context.Background(), 1, 1, false); err != nil || content["title"] != "new" {
+			t.Fatalf("GetUserBanner after update = %v, %v; want new banner", content, err)
+		}
+		if content, _, found, err := s.GetUserBanner(context.Background(), 1, 2, false); err != nil || found {
+			t.Fatalf("GetUserBanner after delete = %v, %v, %v; want not found", content, found, err)
+		}
+	}
+}
+
+func TestRefillWithoutWritesReadsReplica(t *testing.T) {
+	s, db := newLaggingStorage(t, 0)
+	db.replica[1] = models.JSONMap{"title": "replica"}
+	if content, _, _, err := s.GetUserBanner(context.Background(), 1, 1, false); err != nil || content["title"] != "replica" {
+		t.Fatalf("GetUserBanner = %v, %v; want banner from replica", content, err)
+	}
+}
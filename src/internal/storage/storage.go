@@ -1,74 +1,430 @@
 package storage
 
 import (
+	"banner/internal/breaker"
 	"banner/internal/cashe"
+	"banner/internal/inmemory"
+	"banner/internal/jobs"
 	"banner/internal/postgresql"
+	"banner/internal/repository"
+	"banner/internal/sqlite"
 	"banner/models"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
 )
 
+// ErrConflict возвращается при восстановлении баннера, пары фича-тэг которого уже заняты
+var ErrConflict = repository.ErrConflict
+
+// ErrVersionMismatch возвращается, если версия баннера не совпала с ожидаемой
+var ErrVersionMismatch = repository.ErrVersionMismatch
+
+// ErrInvalidWindow возвращается, если изменение делает окно показа баннера пустым
+var ErrInvalidWindow = repository.ErrInvalidWindow
+
+// ErrBatchAborted возвращается для баннеров пакета, не созданных из-за ошибки в другом баннере пакета
+var ErrBatchAborted = errors.New("batch is aborted")
+
 type Storage struct {
-	db    *postgresql.Postgres
-	cache *cashe.Cache
+	db       repository.Repository
+	cache    cashe.Cache
+	negative *cashe.NegativeCache
+	jobs     *jobs.Manager
+	loads    *loadGroup
+	breaker  *breaker.Breaker
+	// hits и misses - обращения к кэшу баннеров из GetUserBanner
+	hits   atomic.Int64
+	misses atomic.Int64
+	// stale - попадания в устаревшие элементы, refreshes - запущенные для них фоновые обновления
+	stale     atomic.Int64
+	refreshes atomic.Int64
+	// lastKnownHits - ответы из кэша, пока база данных недоступна
+	lastKnownHits atomic.Int64
+	warmUp        warmUpConfig
+	// ready устанавливается после прогрева кэша
+	ready atomic.Bool
+	// pinging - идет фоновая проверка базы после ошибки запроса, см. observe
+	pinging atomic.Bool
+	// lastWrite - время последнего изменения баннеров (UnixNano), в течение replicaLag после него
+	// кэш заполняется с основной базы, см. recentlyWritten
+	lastWrite  atomic.Int64
+	replicaLag time.Duration
 }
 
 func NewStorage() *Storage {
-	db := postgresql.NewPostgresRepository()
-	cache := cashe.NewCache()
-	return &Storage{
-		db:    db,
-		cache: cache,
+	return NewStorageWith(newRepository(), cashe.NewCache())
+}
+
+// NewStorageWith создает хранилище поверх готовых репозитория и кэша баннеров.
+// Остальные настройки (кэш ненайденных пар, прогрев, предохранитель) читаются из окружения
+func NewStorageWith(db repository.Repository, cache cashe.Cache) *Storage {
+	s := &Storage{
+		db:         db,
+		cache:      cache,
+		negative:   cashe.NewNegativeCache(),
+		jobs:       jobs.NewManager(),
+		loads:      newLoadGroup(),
+		warmUp:     loadWarmUpConfig(),
+		breaker:    newBreaker(db.Ping),
+		replicaLag: loadReplicaLag(),
+	}
+	db.Listen(s.applyChange, s.resetCaches)
+	return s
+}
+
+// newRepository создает хранилище баннеров, выбранное в STORAGE_BACKEND
+func newRepository() repository.Repository {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "postgres":
+		return postgresql.NewPostgresRepository()
+	case "sqlite":
+		return sqlite.NewSQLiteRepository()
+	case "memory":
+		return inmemory.NewMemoryRepository()
+	default:
+		panic("Unknown STORAGE_BACKEND: " + backend)
+	}
+}
+
+// applyChange удаляет из локальных кэшей баннер, измененный другим экземпляром сервиса
+func (s *Storage) applyChange(change repository.Change) {
+	s.markWritten()
+	s.cache.Remove(change.Id)
+	for _, feature := range change.Features {
+		s.negative.Invalidate(feature, change.Tags)
 	}
 }
 
+// resetCaches очищает локальные кэши, когда уведомления об изменениях могли быть потеряны
+func (s *Storage) resetCaches() {
+	s.markWritten()
+	s.cache.Clear()
+	s.negative.Clear()
+}
+
 func (s *Storage) Insert(record *models.InsertData) (int32, error) {
+	if !s.breaker.Allow() {
+		return 0, ErrUnavailable
+	}
 	id, err := s.db.Insert(record)
+	s.observe(err)
 	if err != nil {
 		return 0, err
 	}
+	s.addInserted(id, record)
+	return id, nil
+}
+
+// InsertBatch создает баннеры records. Если atomic, баннеры создаются в одной транзакции и при ошибке
+// не создается ни один (остальные баннеры получают ErrBatchAborted), иначе каждый баннер создается отдельно.
+// Возвращает идентификаторы баннеров (0, если баннер не создан) и ошибки в порядке records
+func (s *Storage) InsertBatch(records []*models.InsertData, atomic bool) ([]int32, []error) {
+	ids := make([]int32, len(records))
+	errs := make([]error, len(records))
+	if !atomic {
+		for i, record := range records {
+			ids[i], errs[i] = s.Insert(record)
+		}
+		return ids, errs
+	}
+	if !s.breaker.Allow() {
+		for i := range errs {
+			errs[i] = ErrUnavailable
+		}
+		return ids, errs
+	}
+	created, err := s.db.InsertBatch(records)
+	s.observe(err)
+	if err != nil {
+		var batchErr *repository.BatchError
+		isBatchErr := errors.As(err, &batchErr)
+		for i := range errs {
+			switch {
+			case !isBatchErr:
+				errs[i] = err
+			case batchErr.Index == i:
+				errs[i] = batchErr.Err
+			default:
+				errs[i] = ErrBatchAborted
+			}
+		}
+		return ids, errs
+	}
+	for i, record := range records {
+		s.addInserted(created[i], record)
+	}
+	return created, errs
+}
+
+// addInserted кладет созданный баннер в кэш и убирает его пары из кэша ненайденных пар
+func (s *Storage) addInserted(id int32, record *models.InsertData) {
+	s.markWritten()
+	s.negative.Invalidate(record.Feature, record.TagIds)
 	s.cache.AddOne(cashe.Item{
 		BannerID:  id,
 		FeatureID: record.Feature,
 		TagIDs:    record.TagIds,
 		IsActive:  record.IsActive,
+		StartsAt:  record.StartsAt,
+		EndsAt:    record.EndsAt,
 		Content:   record.Content,
 	})
-	return id, nil
 }
 
-func (s *Storage) GetUserBanner(feature, tag int32, fromBD bool) (models.JSONMap, bool, bool, error) {
-	if !fromBD {
-		content, userAccess := s.cache.Get(feature, tag)
-		if content != nil {
-			//fmt.Printf("from cache: feature: %d, tag: %d!\n", feature, tag)
-			return content, userAccess, true, nil
+// GetUserBanner возвращает баннер из кэша, а при промахе загружает его из базы.
+// Одновременные промахи для одной пары фича-тэг обслуживаются одной загрузкой
+func (s *Storage) GetUserBanner(ctx context.Context, feature, tag int32, fromBD bool) (models.JSONMap, bool, bool, error) {
+	if fromBD {
+		// клиент запросил последнюю версию, поэтому кэш не может заменить недоступную базу.
+		// Недоступной база считается при ошибке соединения, остальные ошибки возвращаются как есть
+		if !s.breaker.Allow() {
+			return nil, false, false, ErrUnavailable
+		}
+		res, err := s.loadUserBanner(feature, tag, true)
+		if repository.IsConnectionError(err) {
+			return nil, false, false, ErrUnavailable
+		}
+		if err != nil {
+			return nil, false, false, err
 		}
+		return res.content, res.userAccess, res.found, nil
 	}
-	content, id, userAccess, found, err := s.db.Get(feature, tag)
+	if !s.breaker.Allow() {
+		return s.lastKnown(feature, tag)
+	}
+	content, userAccess, stale := s.cache.Get(feature, tag)
+	if content != nil {
+		//fmt.Printf("from cache: feature: %d, tag: %d!\n", feature, tag)
+		s.hits.Add(1)
+		if stale {
+			// отдаем устаревший баннер сразу и обновляем его в фоне
+			s.stale.Add(1)
+			if s.loads.refresh(cashe.Key{Feature: feature, Tag: tag}, func() (userBanner, error) {
+				return s.loadUserBanner(feature, tag, false)
+			}) {
+				s.refreshes.Add(1)
+			}
+		}
+		return content, userAccess, true, nil
+	}
+	if s.negative.Has(feature, tag) {
+		return nil, false, false, nil
+	}
+	s.misses.Add(1)
+	res, err := s.loads.do(ctx, cashe.Key{Feature: feature, Tag: tag}, func() (userBanner, error) {
+		return s.loadUserBanner(feature, tag, false)
+	})
+	if err != nil && ctx.Err() == nil {
+		// база не ответила: отдаем последнюю известную версию, если она еще есть в кэше
+		if content, userAccess, found, errLast := s.lastKnown(feature, tag); errLast == nil {
+			return content, userAccess, found, nil
+		}
+	}
+	return res.content, res.userAccess, res.found, err
+}
+
+// loadUserBanner читает баннер из базы и кладет результат в кэш (или в кэш ненайденных пар).
+// latest - читать последнюю версию с основной базы, а не с реплики. Сразу после изменения баннеров
+// последняя версия читается всегда, иначе отстающая реплика вернула бы в кэш старое содержимое
+func (s *Storage) loadUserBanner(feature, tag int32, latest bool) (userBanner, error) {
+	generation := s.cache.Generation()
+	negativeGeneration := s.negative.Generation()
+	record, found, err := s.db.Get(feature, tag, latest || s.recentlyWritten())
+	s.observe(err)
 	if err != nil {
-		return nil, false, false, err
+		return userBanner{}, err
 	}
-	s.cache.AddOne(cashe.Item{
-		BannerID:  id,
+	if !found {
+		s.negative.AddIfGeneration(feature, tag, negativeGeneration)
+		return userBanner{}, nil
+	}
+	s.cache.AddOneIfGeneration(cashe.Item{
+		BannerID:  record.BannerId,
 		FeatureID: feature,
 		TagIDs:    []int32{tag},
-		IsActive:  userAccess,
-		Content:   content,
-	})
-	return content, userAccess, found, err
+		IsActive:  record.IsActive,
+		StartsAt:  record.StartsAt,
+		EndsAt:    record.EndsAt,
+		Content:   record.Content,
+	}, generation)
+	return userBanner{
+		content:    record.Content,
+		userAccess: record.IsActive && models.InWindow(record.StartsAt, record.EndsAt, time.Now()),
+		found:      true,
+	}, nil
+}
+
+// Update обновляет баннер и удаляет из кэша все пары фича-тэг, под которыми он был закэширован
+// (в том числе старые, если баннер перенесен на другую фичу или тэги)
+func (s *Storage) Update(id int32, record *models.InsertData) (int32, bool, error) {
+	if !s.breaker.Allow() {
+		return 0, false, ErrUnavailable
+	}
+	version, found, err := s.db.Update(id, record)
+	s.observe(err)
+	if found {
+		s.markWritten()
+		s.cache.Remove(id)
+		s.invalidateNegative(id)
+	}
+	return version, found, err
+}
+
+func (s *Storage) Delete(id int32, expectedVersion int32, info models.AuditInfo) (bool, error) {
+	if !s.breaker.Allow() {
+		return false, ErrUnavailable
+	}
+	found, err := s.db.Delete(id, expectedVersion, info)
+	s.observe(err)
+	if found {
+		s.markWritten()
+		s.cache.Remove(id)
+	}
+	return found, err
 }
 
-func (s *Storage) Update(id int32, record *models.InsertData) (bool, error) {
-	return s.db.Update(id, record)
+// invalidateNegative удаляет из кэша ненайденных пар пары фича-тэг, под которыми теперь находится баннер id
+func (s *Storage) invalidateNegative(id int32) {
+	banner, found, err := s.db.GetById(id)
+	if err != nil || !found {
+		s.negative.Clear()
+		return
+	}
+	s.negative.Invalidate(banner.FeatureId, banner.TagIds)
+}
+
+// CacheStats возвращает статистику кэша баннеров и отдельно кэша ненайденных пар
+func (s *Storage) CacheStats() models.CacheStatsGet200Response {
+	stats := s.cache.Stats()
+	negative := s.negative.Stats()
+	return models.CacheStatsGet200Response{
+		Hits:         s.hits.Load(),
+		Misses:       s.misses.Load(),
+		Deduplicated: s.loads.deduplicated.Load(),
+		Stale:        s.stale.Load(),
+		Refreshes:    s.refreshes.Load(),
+		LastKnown:    s.lastKnownHits.Load(),
+		Breaker:      s.breaker.State(),
+		Entries:      stats.Entries,
+		Bytes:        stats.Bytes,
+		Evictions:    stats.Evictions,
+		Expired:      stats.Expired,
+		Negative: models.CacheStatsGet200ResponseNegative{
+			Hits:          negative.Hits,
+			Invalidations: negative.Invalidations,
+			Entries:       negative.Entries,
+		},
+	}
 }
 
-func (s *Storage) Delete(id int32) (bool, error) {
-	return s.db.Delete(id)
+func (s *Storage) GetById(id int32) (models.BannerGet200ResponseInner, bool, error) {
+	if !s.breaker.Allow() {
+		return models.BannerGet200ResponseInner{}, false, ErrUnavailable
+	}
+	banner, found, err := s.db.GetById(id)
+	s.observe(err)
+	return banner, found, unavailable(err)
 }
 
-func (s *Storage) GetMany(featureId int32, tagId int32, limit int32, offset int32) ([]map[string]interface{}, error) {
-	return s.db.GetMany(featureId, tagId, limit, offset)
+func (s *Storage) Versions(id int32) ([]models.BannerIdVersionsGet200ResponseInner, bool, error) {
+	if !s.breaker.Allow() {
+		return nil, false, ErrUnavailable
+	}
+	versions, found, err := s.db.Versions(id)
+	s.observe(err)
+	return versions, found, unavailable(err)
+}
+
+func (s *Storage) RestoreVersion(id int32, version int32, info models.AuditInfo) (bool, error) {
+	if !s.breaker.Allow() {
+		return false, ErrUnavailable
+	}
+	found, err := s.db.RestoreVersion(id, version, info)
+	s.observe(err)
+	err = unavailable(err)
+	if found {
+		s.markWritten()
+		s.cache.Remove(id)
+		s.invalidateNegative(id)
+	}
+	return found, err
+}
+
+func (s *Storage) Trash(limit int32, offset int32) ([]models.BannerTrashGet200ResponseInner, error) {
+	if !s.breaker.Allow() {
+		return nil, ErrUnavailable
+	}
+	trash, err := s.db.Trash(limit, offset)
+	s.observe(err)
+	return trash, unavailable(err)
+}
+
+func (s *Storage) Restore(id int32, info models.AuditInfo) (bool, error) {
+	if !s.breaker.Allow() {
+		return false, ErrUnavailable
+	}
+	found, err := s.db.Restore(id, info)
+	s.observe(err)
+	err = unavailable(err)
+	if found {
+		s.markWritten()
+		s.cache.Remove(id)
+		s.invalidateNegative(id)
+	}
+	return found, err
+}
+
+func (s *Storage) Audit(filter models.AuditFilter) ([]models.AuditGet200ResponseInner, error) {
+	if !s.breaker.Allow() {
+		return nil, ErrUnavailable
+	}
+	records, err := s.db.Audit(filter)
+	s.observe(err)
+	return records, unavailable(err)
+}
+
+// DeleteMany запускает фоновое удаление баннеров с фичей featureId и/или тэгом tagId
+// и возвращает идентификатор задачи
+func (s *Storage) DeleteMany(featureId int32, tagId int32, info models.AuditInfo) (int32, error) {
+	if !s.breaker.Allow() {
+		return 0, ErrUnavailable
+	}
+	ids, err := s.db.FindIds(featureId, tagId)
+	s.observe(err)
+	if err != nil {
+		return 0, unavailable(err)
+	}
+	return s.jobs.Enqueue(ids, func(id int32) (bool, error) {
+		return s.Delete(id, 0, info)
+	}), nil
+}
+
+func (s *Storage) Job(id int32) (models.JobsIdGet200Response, bool) {
+	return s.jobs.Get(id)
+}
+
+func (s *Storage) GetMany(filter models.BannerFilter) ([]map[string]interface{}, int64, error) {
+	if !s.breaker.Allow() {
+		return nil, 0, ErrUnavailable
+	}
+	res, total, err := s.db.GetMany(filter)
+	s.observe(err)
+	return res, total, unavailable(err)
 }
 
 func (s *Storage) Stop() error {
+	s.ready.Store(false)
+	s.jobs.Wait()
+	s.breaker.Stop()
+	if err := s.saveHot(); err != nil {
+		log.Println(err.Error())
+	}
+	if err := s.cache.Close(); err != nil {
+		return err
+	}
 	return s.db.Stop()
 }
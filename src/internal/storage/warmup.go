@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"banner/internal/cashe"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Режимы прогрева кэша при запуске (CACHE_WARMUP)
+const (
+	warmUpOff = "off"
+	// warmUpAll загружает активные баннеры, начиная с последних измененных
+	warmUpAll = "all"
+	// warmUpTop загружает пары фича-тэг, которые запрашивались последними перед остановкой сервиса
+	warmUpTop = "top"
+)
+
+const defaultWarmUpTimeout = 30 * time.Second
+
+type warmUpConfig struct {
+	mode string
+	// limit - максимальное количество баннеров (all) или пар фича-тэг (top), 0 - без ограничения
+	limit   int
+	timeout time.Duration
+	// file - файл, в который при остановке сохраняются запрашиваемые пары для режима top
+	file string
+}
+
+func loadWarmUpConfig() warmUpConfig {
+	cfg := warmUpConfig{
+		mode:    os.Getenv("CACHE_WARMUP"),
+		timeout: defaultWarmUpTimeout,
+		file:    os.Getenv("CACHE_WARMUP_FILE"),
+	}
+	switch cfg.mode {
+	case "":
+		cfg.mode = warmUpOff
+	case warmUpOff, warmUpAll, warmUpTop:
+	default:
+		panic("Unknown CACHE_WARMUP: " + cfg.mode)
+	}
+	if value := os.Getenv("CACHE_WARMUP_LIMIT"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			panic("Can't parse CACHE_WARMUP_LIMIT: " + err.Error())
+		}
+		cfg.limit = limit
+	}
+	if value := os.Getenv("CACHE_WARMUP_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			panic("Can't parse CACHE_WARMUP_TIMEOUT: " + err.Error())
+		}
+		cfg.timeout = timeout
+	}
+	if cfg.mode == warmUpTop && cfg.file == "" {
+		panic("CACHE_WARMUP_FILE is required for CACHE_WARMUP=top")
+	}
+	// Redis не отслеживает запрашиваемые пары (RedisCache.Hot), а кэш в нем и так переживает перезапуск сервиса
+	if cfg.mode == warmUpTop && os.Getenv("CACHE_BACKEND") == "redis" {
+		panic("CACHE_WARMUP=top is not supported with CACHE_BACKEND=redis")
+	}
+	return cfg
+}
+
+// WarmUp загружает баннеры в кэш, но не дольше CACHE_WARMUP_TIMEOUT. Запросы обрабатываются и во время
+// прогрева, а после его завершения (в том числе с ошибкой или по таймауту) сервис считается готовым, см. Ready
+func (s *Storage) WarmUp(ctx context.Context) error {
+	defer s.ready.Store(true)
+	if s.warmUp.mode == warmUpOff {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.warmUp.timeout)
+	defer cancel()
+	start := time.Now()
+	var pairs int
+	var err error
+	if s.warmUp.mode == warmUpAll {
+		pairs, err = s.warmUpAll(ctx)
+	} else {
+		pairs, err = s.warmUpTop(ctx)
+	}
+	log.Printf("Cache warm-up (%s): %d pairs loaded in %s", s.warmUp.mode, pairs, time.Since(start))
+	if err != nil {
+		return errors.New("can't warm up cache: " + err.Error())
+	}
+	return nil
+}
+
+// Ready сообщает, что прогрев кэша завершен и сервис готов обрабатывать запросы
+func (s *Storage) Ready() bool {
+	return s.ready.Load()
+}
+
+func (s *Storage) warmUpAll(ctx context.Context) (int, error) {
+	banners, err := s.db.ActiveBanners(ctx, s.warmUp.limit)
+	if err != nil {
+		return 0, err
+	}
+	pairs := 0
+	for _, banner := range banners {
+		s.cache.AddOne(cashe.Item{
+			BannerID:  banner.BannerId,
+			FeatureID: banner.FeatureId,
+			TagIDs:    banner.TagIds,
+			IsActive:  banner.IsActive,
+			StartsAt:  banner.StartsAt,
+			EndsAt:    banner.EndsAt,
+			Content:   banner.Content,
+		})
+		pairs += len(banner.TagIds)
+	}
+	return pairs, nil
+}
+
+func (s *Storage) warmUpTop(ctx context.Context) (int, error) {
+	bytes, err := os.ReadFile(s.warmUp.file)
+	if err != nil {
+		// файла нет при первом запуске
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var keys []cashe.Key
+	if err := json.Unmarshal(bytes, &keys); err != nil {
+		return 0, err
+	}
+	if s.warmUp.limit > 0 && len(keys) > s.warmUp.limit {
+		keys = keys[:s.warmUp.limit]
+	}
+	for i, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if _, err := s.loadUserBanner(key.Feature, key.Tag, false); err != nil {
+			return i, err
+		}
+	}
+	return len(keys), nil
+}
+
+// saveHot сохраняет последние запрошенные пары фича-тэг для прогрева кэша при следующем запуске
+func (s *Storage) saveHot() error {
+	if s.warmUp.mode != warmUpTop {
+		return nil
+	}
+	bytes, err := json.Marshal(s.cache.Hot(s.warmUp.limit))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.warmUp.file, bytes, 0644); err != nil {
+		return errors.New("can't save hot pairs: " + err.Error())
+	}
+	return nil
+}
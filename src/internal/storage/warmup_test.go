@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadyAfterWarmUp(t *testing.T) {
+	s := &Storage{warmUp: warmUpConfig{mode: warmUpOff}}
+	// запросы обрабатываются и до прогрева, но сервис еще не готов
+	if s.Ready() {
+		t.Fatal("storage is ready before warm-up")
+	}
+	if err := s.WarmUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Ready() {
+		t.Fatal("storage is not ready after warm-up")
+	}
+}
+
+func TestWarmUpTopWithRedis(t *testing.T) {
+	t.Setenv("CACHE_WARMUP", warmUpTop)
+	t.Setenv("CACHE_WARMUP_FILE", "hot_pairs.json")
+	t.Setenv("CACHE_BACKEND", "redis")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("CACHE_WARMUP=top with Redis cache is accepted")
+		}
+	}()
+	loadWarmUpConfig()
+}
@@ -11,9 +11,11 @@ package main
 
 import (
 	"banner/internal/env"
+	"banner/internal/postgresql"
 	openapi "banner/restapi"
 	"context"
 	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -24,14 +26,21 @@ import (
 
 func main() {
 	env.LoadEnv()
+	// ./avito --config=env/.env migrate up | down | status | to <version>
+	if flag.Arg(0) == "migrate" {
+		if err := postgresql.Migrate(context.Background(), flag.Args()[1:], os.Stdout); err != nil {
+			log.Fatalf("Ошибка миграции: %v", err)
+		}
+		return
+	}
 	DefaultAPIService := openapi.NewDefaultAPIService()
 	DefaultAPIController := openapi.NewDefaultAPIController(DefaultAPIService)
 
 	// Создаем маршрутизатор и передаем контроллер
 	router := openapi.NewRouter(DefaultAPIController)
 	// Создаем контекст для управления сервером
-	_, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, stopWarmUp := context.WithCancel(context.Background())
+	defer stopWarmUp()
 
 	// Запускаем сервер в отдельной горутине с использованием контекста
 	server := &http.Server{
@@ -39,11 +48,19 @@ func main() {
 		Handler: router,
 	}
 	go func() {
-		log.Printf("Server started at port " + os.Getenv("PORT"))
+		log.Printf("Server started at port %s", os.Getenv("PORT"))
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("Ошибка запуска сервера: %v", err)
 		}
 	}()
+	// Прогреваем кэш в фоне: пока прогрев не завершен, GET /ready отвечает 503
+	warmedUp := make(chan struct{})
+	go func() {
+		defer close(warmedUp)
+		if err := DefaultAPIService.WarmUp(ctx); err != nil {
+			log.Println(err.Error())
+		}
+	}()
 
 	// Ожидание сигнала завершения работы сервера
 	stop := make(chan os.Signal, 1)
@@ -57,6 +74,8 @@ func main() {
 	if err := server.Shutdown(ctxShutdown); err != nil {
 		log.Fatalf("Ошибка остановки сервера: %v", err)
 	}
+	stopWarmUp()
+	<-warmedUp
 	if err := DefaultAPIService.Stop(); err != nil {
 		log.Fatalf("Ошибка остановки сервера: %v", err)
 	}
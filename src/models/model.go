@@ -2,8 +2,12 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type InsertData struct {
@@ -11,13 +15,216 @@ type InsertData struct {
 	TagIds   []int32
 	Content  JSONMap
 	IsActive bool
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	// ClearStartsAt и ClearEndsAt сбрасывают границы окна показа при изменении баннера
+	ClearStartsAt bool
+	ClearEndsAt   bool
+	// SetIsActive - флаг активности задан при изменении баннера, в том числе значением false
+	SetIsActive bool
+	// ExpectedVersion - версия баннера, которую ожидает клиент (If-Match). 0 - без проверки
+	ExpectedVersion int32
+	AuditInfo
+}
+
+// Window возвращает окно показа баннера с сохраненными границами startsAt и endsAt после изменения d
+func (d *InsertData) Window(startsAt, endsAt *time.Time) (*time.Time, *time.Time) {
+	if d.StartsAt != nil || d.ClearStartsAt {
+		startsAt = d.StartsAt
+	}
+	if d.EndsAt != nil || d.ClearEndsAt {
+		endsAt = d.EndsAt
+	}
+	return startsAt, endsAt
+}
+
+// AuditInfo - кто и в рамках какого запроса изменяет баннер
+type AuditInfo struct {
+	Actor     string
+	RequestId string
+}
+
+// AuditFilter - условия выборки из журнала изменений. Нулевые значения не учитываются
+type AuditFilter struct {
+	BannerId int32
+	Actor    string
+	From     time.Time
+	To       time.Time
+	Limit    int32
+	Offset   int32
+}
+
+// BannerFilter - условия выборки баннеров в GET /banner. Нулевые значения не учитываются
+type BannerFilter struct {
+	// FeatureIds, TagIds - баннер должен иметь одну из фич FeatureIds и хотя бы один из тэгов TagIds
+	FeatureIds []int32
+	TagIds     []int32
+	IsActive   *bool
+	// Границы времени создания и изменения баннера (включительно)
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	// ContentFields - значения полей содержимого: ключ - путь к полю через точку (title, meta.lang)
+	ContentFields map[string]string
+	// ContentContains - JSON-объект, который должно содержать содержимое баннера (как оператор @> для jsonb)
+	ContentContains JSONMap
+	// Sort - порядок баннеров. По умолчанию - по возрастанию id
+	Sort BannerSort
+	// After - постраничный вывод по курсору: баннеры начинаются сразу после ключа курсора в порядке Sort
+	After  *BannerCursor
+	Limit  int32
+	Offset int32
+}
+
+// Поля, по которым можно упорядочить баннеры (параметр sort)
+const (
+	SortById        = "id"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+// BannerSort - порядок баннеров: по полю Field, при равенстве - по id в том же направлении
+type BannerSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseBannerSort разбирает параметр sort: имя поля, с минусом - по убыванию (-updated_at)
+func ParseBannerSort(s string) (BannerSort, error) {
+	field, desc := strings.CutPrefix(s, "-")
+	switch field {
+	case SortById, SortByCreatedAt, SortByUpdatedAt:
+		return BannerSort{Field: field, Desc: desc}, nil
+	}
+	return BannerSort{}, errors.New("invalid sort: " + s)
+}
+
+func (s BannerSort) String() string {
+	field := s.Field
+	if field == "" {
+		field = SortById
+	}
+	if s.Desc {
+		return "-" + field
+	}
+	return field
+}
+
+// Before сообщает, что баннер с ключом a идет раньше баннера с ключом b
+func (s BannerSort) Before(a, b BannerKey) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.Before(b.Time) != s.Desc
+	}
+	return a.Id != b.Id && (a.Id < b.Id) != s.Desc
+}
+
+// Key возвращает ключ баннера banner из ответа GET /banner для порядка s
+func (s BannerSort) Key(banner map[string]interface{}) BannerKey {
+	key := BannerKey{Id: banner["banner_id"].(int32)}
+	switch s.Field {
+	case SortByCreatedAt:
+		key.Time = banner["created_at"].(time.Time)
+	case SortByUpdatedAt:
+		key.Time = banner["updated_at"].(time.Time)
+	}
+	return key
+}
+
+// BannerKey - значения, по которым упорядочиваются баннеры: время из поля сортировки (нулевое при
+// сортировке по id) и идентификатор
+type BannerKey struct {
+	Time time.Time `json:"u"`
+	Id   int32     `json:"i"`
+}
+
+// BannerCursor - позиция в списке баннеров, упорядоченном по Sort (см. BannerSort.String)
+type BannerCursor struct {
+	Sort string `json:"s,omitempty"`
+	BannerKey
+}
+
+// First сообщает, что курсор указывает на начало списка (первую страницу)
+func (c BannerCursor) First() bool {
+	return c.Id == 0
+}
+
+// String кодирует курсор в непрозрачную строку для параметра cursor
+func (c BannerCursor) String() string {
+	bytes, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// ParseBannerCursor декодирует курсор, полученный от BannerCursor.String. Пустая строка - первая страница
+func ParseBannerCursor(s string) (BannerCursor, error) {
+	var res BannerCursor
+	if s == "" {
+		return res, nil
+	}
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return res, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return res, errors.New("invalid cursor")
+	}
+	return res, nil
+}
+
+// InWindow проверяет, что момент now попадает в окно показа баннера [startsAt, endsAt).
+// Незаданная граница окна не ограничивает показ
+func InWindow(startsAt, endsAt *time.Time, now time.Time) bool {
+	if startsAt != nil && now.Before(*startsAt) {
+		return false
+	}
+	if endsAt != nil && !now.Before(*endsAt) {
+		return false
+	}
+	return true
+}
+
+// ValidWindow проверяет, что окно показа баннера не пустое. Незаданная граница окна не ограничивает показ
+func ValidWindow(startsAt, endsAt *time.Time) bool {
+	return startsAt == nil || endsAt == nil || endsAt.After(*startsAt)
+}
+
+// NullableTime - необязательное время в запросе на изменение. Set отличает явный null (сброс значения)
+// от отсутствующего поля (значение не изменяется)
+type NullableTime struct {
+	Value *time.Time
+	Set   bool
+}
+
+// UnmarshalJSON - реализация интерфейса json.Unmarshaler
+func (t *NullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if string(data) == "null" {
+		t.Value = nil
+		return nil
+	}
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.Value = &value
+	return nil
+}
+
+// MarshalJSON - реализация интерфейса json.Marshaler
+func (t NullableTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Value)
 }
 
 type JSONMap map[string]interface{}
 
-// Value - реализация интерфейса driver.Valuer
-func (j *JSONMap) Value() (driver.Value, error) {
-	return json.Marshal(j)
+// Value - реализация интерфейса driver.Valuer. Приемник - значение, чтобы драйверы database/sql
+// (например, SQLite) сериализовали содержимое в JSON, а не отклоняли map
+func (j JSONMap) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
 }
 
 // Scan - реализация интерфейса sql.Scanner
@@ -28,9 +235,14 @@ func (j *JSONMap) Scan(value interface{}) error {
 		return nil
 	}
 
-	// Преобразуем значение в []byte
-	bytes, ok := value.([]byte)
-	if !ok {
+	// Преобразуем значение в []byte (SQLite возвращает JSON строкой)
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
 		return fmt.Errorf("ошибка преобразования типа %T в []byte", value)
 	}
 
@@ -44,3 +256,35 @@ func (j *JSONMap) Scan(value interface{}) error {
 	*j = data
 	return nil
 }
+
+type TagList []int32
+
+// Value - реализация интерфейса driver.Valuer
+func (t TagList) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+// Scan - реализация интерфейса sql.Scanner
+func (t *TagList) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("ошибка преобразования типа %T в []byte", value)
+	}
+
+	var data []int32
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return err
+	}
+	*t = data
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+import (
+	"time"
+)
+
+type AuditGet200ResponseInner struct {
+
+	// Идентификатор записи журнала
+	Id int64 `json:"id,omitempty"`
+
+	// Идентификатор баннера
+	BannerId int32 `json:"banner_id,omitempty"`
+
+	// Пользователь, выполнивший изменение
+	Actor string `json:"actor,omitempty"`
+
+	// Действие: create, update, delete, restore, restore_version, purge
+	Action string `json:"action,omitempty"`
+
+	// Идентификатор запроса (X-Request-Id)
+	RequestId string `json:"request_id,omitempty"`
+
+	// Состояние баннера до изменения
+	Before map[string]interface{} `json:"before,omitempty"`
+
+	// Состояние баннера после изменения
+	After map[string]interface{} `json:"after,omitempty"`
+
+	// Измененные поля
+	Diff map[string]interface{} `json:"diff,omitempty"`
+
+	// Дата изменения
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// AssertAuditGet200ResponseInnerRequired checks if the required fields are not zero-ed
+func AssertAuditGet200ResponseInnerRequired(obj AuditGet200ResponseInner) error {
+	return nil
+}
+
+// AssertAuditGet200ResponseInnerConstraints checks if the values respects the defined constraints
+func AssertAuditGet200ResponseInnerConstraints(obj AuditGet200ResponseInner) error {
+	return nil
+}
@@ -22,6 +22,12 @@ type BannerIdDeleteRequest struct {
 
 	// Флаг активности баннера
 	IsActive *bool `json:"is_active,omitempty"`
+
+	// Начало показа баннера (RFC 3339 с часовым поясом). null снимает ограничение
+	StartsAt NullableTime `json:"starts_at,omitzero"`
+
+	// Окончание показа баннера (RFC 3339 с часовым поясом). null снимает ограничение
+	EndsAt NullableTime `json:"ends_at,omitzero"`
 }
 
 // AssertBannerIdDeleteRequestRequired checks if the required fields are not zero-ed
@@ -0,0 +1,54 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+import (
+	"time"
+)
+
+type BannerIdVersionsGet200ResponseInner struct {
+
+	// Номер версии баннера
+	Version int32 `json:"version,omitempty"`
+
+	// Идентификаторы тэгов
+	TagIds []int32 `json:"tag_ids,omitempty"`
+
+	// Идентификатор фичи
+	FeatureId int32 `json:"feature_id,omitempty"`
+
+	// Содержимое баннера
+	Content map[string]interface{} `json:"content,omitempty"`
+
+	// Флаг активности баннера
+	IsActive bool `json:"is_active"`
+
+	// Начало показа баннера
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+
+	// Окончание показа баннера
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+
+	// Автор версии
+	Author string `json:"author,omitempty"`
+
+	// Дата создания версии
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// AssertBannerIdVersionsGet200ResponseInnerRequired checks if the required fields are not zero-ed
+func AssertBannerIdVersionsGet200ResponseInnerRequired(obj BannerIdVersionsGet200ResponseInner) error {
+	return nil
+}
+
+// AssertBannerIdVersionsGet200ResponseInnerConstraints checks if the values respects the defined constraints
+func AssertBannerIdVersionsGet200ResponseInnerConstraints(obj BannerIdVersionsGet200ResponseInner) error {
+	return nil
+}
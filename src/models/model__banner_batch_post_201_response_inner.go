@@ -0,0 +1,33 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+// BannerBatchPost201ResponseInner - результат создания одного баннера из POST /banner/batch
+type BannerBatchPost201ResponseInner struct {
+
+	// Номер баннера в запросе, начиная с нуля
+	Index int32 `json:"index"`
+
+	// Идентификатор созданного баннера
+	BannerId int32 `json:"banner_id,omitempty"`
+
+	// Причина, по которой баннер не создан
+	Error string `json:"error,omitempty"`
+}
+
+// AssertBannerBatchPost201ResponseInnerRequired checks if the required fields are not zero-ed
+func AssertBannerBatchPost201ResponseInnerRequired(obj BannerBatchPost201ResponseInner) error {
+	return nil
+}
+
+// AssertBannerBatchPost201ResponseInnerConstraints checks if the values respects the defined constraints
+func AssertBannerBatchPost201ResponseInnerConstraints(obj BannerBatchPost201ResponseInner) error {
+	return nil
+}
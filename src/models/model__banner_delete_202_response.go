@@ -0,0 +1,26 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+type BannerDelete202Response struct {
+
+	// Идентификатор задачи удаления
+	JobId int32 `json:"job_id,omitempty"`
+}
+
+// AssertBannerDelete202ResponseRequired checks if the required fields are not zero-ed
+func AssertBannerDelete202ResponseRequired(obj BannerDelete202Response) error {
+	return nil
+}
+
+// AssertBannerDelete202ResponseConstraints checks if the values respects the defined constraints
+func AssertBannerDelete202ResponseConstraints(obj BannerDelete202Response) error {
+	return nil
+}
@@ -0,0 +1,33 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+// BannerGet200Response - страница баннеров при постраничном выводе по курсору (параметр cursor)
+type BannerGet200Response struct {
+
+	// Баннеры страницы в порядке параметра sort (по умолчанию updated_at)
+	Banners []map[string]interface{} `json:"banners"`
+
+	// Количество баннеров, подходящих под фильтры, на всех страницах
+	Total int64 `json:"total"`
+
+	// Курсор следующей страницы. Отсутствует, если страница последняя
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AssertBannerGet200ResponseRequired checks if the required fields are not zero-ed
+func AssertBannerGet200ResponseRequired(obj BannerGet200Response) error {
+	return nil
+}
+
+// AssertBannerGet200ResponseConstraints checks if the values respects the defined constraints
+func AssertBannerGet200ResponseConstraints(obj BannerGet200Response) error {
+	return nil
+}
@@ -30,6 +30,15 @@ type BannerGet200ResponseInner struct {
 	// Флаг активности баннера
 	IsActive bool `json:"is_active,omitempty"`
 
+	// Начало показа баннера
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+
+	// Окончание показа баннера
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+
+	// Версия баннера, используется как ETag
+	Version int32 `json:"version,omitempty"`
+
 	// Дата создания баннера
 	CreatedAt time.Time `json:"created_at,omitempty"`
 
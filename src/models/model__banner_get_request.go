@@ -9,6 +9,10 @@
 
 package models
 
+import (
+	"time"
+)
+
 type BannerGetRequest struct {
 
 	// Идентификаторы тэгов
@@ -22,6 +26,12 @@ type BannerGetRequest struct {
 
 	// Флаг активности баннера
 	IsActive bool `json:"is_active,omitempty"`
+
+	// Начало показа баннера (RFC 3339 с часовым поясом)
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+
+	// Окончание показа баннера (RFC 3339 с часовым поясом)
+	EndsAt *time.Time `json:"ends_at,omitempty"`
 }
 
 // AssertBannerGetRequestRequired checks if the required fields are not zero-ed
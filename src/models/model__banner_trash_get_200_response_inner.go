@@ -0,0 +1,57 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+import (
+	"time"
+)
+
+type BannerTrashGet200ResponseInner struct {
+
+	// Идентификатор баннера
+	BannerId int32 `json:"banner_id,omitempty"`
+
+	// Идентификаторы тэгов
+	TagIds []int32 `json:"tag_ids,omitempty"`
+
+	// Идентификатор фичи
+	FeatureId int32 `json:"feature_id,omitempty"`
+
+	// Содержимое баннера
+	Content map[string]interface{} `json:"content,omitempty"`
+
+	// Флаг активности баннера
+	IsActive bool `json:"is_active,omitempty"`
+
+	// Начало показа баннера
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+
+	// Окончание показа баннера
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+
+	// Дата создания баннера
+	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	// Дата обновления баннера
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+
+	// Дата удаления баннера
+	DeletedAt time.Time `json:"deleted_at,omitempty"`
+}
+
+// AssertBannerTrashGet200ResponseInnerRequired checks if the required fields are not zero-ed
+func AssertBannerTrashGet200ResponseInnerRequired(obj BannerTrashGet200ResponseInner) error {
+	return nil
+}
+
+// AssertBannerTrashGet200ResponseInnerConstraints checks if the values respects the defined constraints
+func AssertBannerTrashGet200ResponseInnerConstraints(obj BannerTrashGet200ResponseInner) error {
+	return nil
+}
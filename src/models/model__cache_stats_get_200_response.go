@@ -0,0 +1,61 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+type CacheStatsGet200Response struct {
+
+	// Количество запросов баннера пользователем, обслуженных из кэша
+	Hits int64 `json:"hits"`
+
+	// Количество запросов баннера пользователем, для которых пришлось обратиться к базе данных
+	Misses int64 `json:"misses"`
+
+	// Количество промахов, которые дождались уже выполняющейся загрузки баннера из базы вместо своей
+	Deduplicated int64 `json:"deduplicated"`
+
+	// Количество запросов, обслуженных устаревшим элементом кэша (между CACHE_EXPIRATION и CACHE_HARD_EXPIRATION)
+	Stale int64 `json:"stale"`
+
+	// Количество фоновых обновлений устаревших элементов
+	Refreshes int64 `json:"refreshes"`
+
+	// Количество запросов, обслуженных последней известной версией баннера, пока база данных недоступна
+	LastKnown int64 `json:"last_known"`
+
+	// Состояние предохранителя базы данных: closed или open
+	Breaker string `json:"breaker,omitempty"`
+
+	// Текущее количество элементов в кэше
+	Entries int64 `json:"entries"`
+
+	// Приблизительный суммарный размер содержимого баннеров в кэше, байт
+	Bytes int64 `json:"bytes"`
+
+	// Количество элементов, вытесненных при превышении CACHE_MAX_ENTRIES или CACHE_MAX_BYTES
+	Evictions int64 `json:"evictions"`
+
+	// Количество элементов, удаленных по истечении CACHE_EXPIRATION
+	Expired int64 `json:"expired"`
+
+	Negative CacheStatsGet200ResponseNegative `json:"negative"`
+}
+
+// AssertCacheStatsGet200ResponseRequired checks if the required fields are not zero-ed
+func AssertCacheStatsGet200ResponseRequired(obj CacheStatsGet200Response) error {
+	if err := AssertCacheStatsGet200ResponseNegativeRequired(obj.Negative); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AssertCacheStatsGet200ResponseConstraints checks if the values respects the defined constraints
+func AssertCacheStatsGet200ResponseConstraints(obj CacheStatsGet200Response) error {
+	return nil
+}
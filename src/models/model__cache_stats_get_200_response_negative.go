@@ -0,0 +1,33 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+// CacheStatsGet200ResponseNegative - Статистика кэша пар фича-тэг, для которых баннер не найден
+type CacheStatsGet200ResponseNegative struct {
+
+	// Количество запросов, на которые ответ 404 дан из кэша
+	Hits int64 `json:"hits"`
+
+	// Количество записей, удаленных из кэша после создания баннера для пары
+	Invalidations int64 `json:"invalidations"`
+
+	// Текущее количество записей в кэше
+	Entries int64 `json:"entries"`
+}
+
+// AssertCacheStatsGet200ResponseNegativeRequired checks if the required fields are not zero-ed
+func AssertCacheStatsGet200ResponseNegativeRequired(obj CacheStatsGet200ResponseNegative) error {
+	return nil
+}
+
+// AssertCacheStatsGet200ResponseNegativeConstraints checks if the values respects the defined constraints
+func AssertCacheStatsGet200ResponseNegativeConstraints(obj CacheStatsGet200ResponseNegative) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+import (
+	"time"
+)
+
+type JobsIdGet200Response struct {
+
+	// Идентификатор задачи
+	JobId int32 `json:"job_id,omitempty"`
+
+	// Состояние задачи: queued, running, done, failed
+	Status string `json:"status,omitempty"`
+
+	// Количество баннеров, которые нужно удалить
+	Total int32 `json:"total"`
+
+	// Количество обработанных баннеров
+	Processed int32 `json:"processed"`
+
+	// Количество удаленных баннеров
+	Deleted int32 `json:"deleted"`
+
+	// Количество баннеров, которые не удалось удалить
+	Failed int32 `json:"failed"`
+
+	// Последняя ошибка при выполнении задачи
+	Error string `json:"error,omitempty"`
+
+	// Дата создания задачи
+	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	// Дата последнего изменения состояния задачи
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// AssertJobsIdGet200ResponseRequired checks if the required fields are not zero-ed
+func AssertJobsIdGet200ResponseRequired(obj JobsIdGet200Response) error {
+	return nil
+}
+
+// AssertJobsIdGet200ResponseConstraints checks if the values respects the defined constraints
+func AssertJobsIdGet200ResponseConstraints(obj JobsIdGet200Response) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+/*
+ * Сервис баннеров
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package models
+
+type ReadyGet200Response struct {
+
+	// Состояние сервиса: ready или warming_up
+	Status string `json:"status,omitempty"`
+}
+
+// AssertReadyGet200ResponseRequired checks if the required fields are not zero-ed
+func AssertReadyGet200ResponseRequired(obj ReadyGet200Response) error {
+	return nil
+}
+
+// AssertReadyGet200ResponseConstraints checks if the values respects the defined constraints
+func AssertReadyGet200ResponseConstraints(obj ReadyGet200Response) error {
+	return nil
+}
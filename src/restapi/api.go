@@ -4,16 +4,28 @@ import (
 	"banner/models"
 	"context"
 	"net/http"
+	"time"
 )
 
 // DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
 // The DefaultAPIRouter implementation should parse necessary information from the http request,
 // pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
 type DefaultAPIRouter interface {
+	AuditGet(http.ResponseWriter, *http.Request)
+	BannerBatchPost(http.ResponseWriter, *http.Request)
+	BannerDelete(http.ResponseWriter, *http.Request)
 	BannerGet(http.ResponseWriter, *http.Request)
 	BannerIdDelete(http.ResponseWriter, *http.Request)
+	BannerIdGet(http.ResponseWriter, *http.Request)
 	BannerIdPatch(http.ResponseWriter, *http.Request)
+	BannerIdRestorePost(http.ResponseWriter, *http.Request)
+	BannerIdVersionsGet(http.ResponseWriter, *http.Request)
+	BannerIdVersionsVersionRestorePost(http.ResponseWriter, *http.Request)
 	BannerPost(http.ResponseWriter, *http.Request)
+	BannerTrashGet(http.ResponseWriter, *http.Request)
+	CacheStatsGet(http.ResponseWriter, *http.Request)
+	JobsIdGet(http.ResponseWriter, *http.Request)
+	ReadyGet(http.ResponseWriter, *http.Request)
 	UserBannerGet(http.ResponseWriter, *http.Request)
 }
 
@@ -22,10 +34,22 @@ type DefaultAPIRouter interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type DefaultAPIServicer interface {
-	BannerGet(context.Context, string, int32, int32, int32, int32) (ImplResponse, error)
-	BannerIdDelete(context.Context, int32, string) (ImplResponse, error)
-	BannerIdPatch(context.Context, int32, models.BannerIdDeleteRequest, string) (ImplResponse, error)
+	AuditGet(context.Context, string, int32, string, time.Time, time.Time, int32, int32) (ImplResponse, error)
+	BannerBatchPost(context.Context, []models.BannerGetRequest, string, string) (ImplResponse, error)
+	BannerDelete(context.Context, string, int32, int32) (ImplResponse, error)
+	BannerGet(context.Context, string, []int32, []int32, *bool, time.Time, time.Time, time.Time, time.Time, models.JSONMap, map[string]string, *models.BannerSort, *models.BannerCursor, int32, int32) (ImplResponse, error)
+	BannerIdDelete(context.Context, int32, string, string) (ImplResponse, error)
+	BannerIdGet(context.Context, int32, string) (ImplResponse, error)
+	BannerIdPatch(context.Context, int32, models.BannerIdDeleteRequest, string, string) (ImplResponse, error)
+	BannerIdRestorePost(context.Context, int32, string) (ImplResponse, error)
+	BannerIdVersionsGet(context.Context, int32, string) (ImplResponse, error)
+	BannerIdVersionsVersionRestorePost(context.Context, int32, int32, string) (ImplResponse, error)
 	BannerPost(context.Context, models.BannerGetRequest, string) (ImplResponse, error)
+	BannerTrashGet(context.Context, string, int32, int32) (ImplResponse, error)
+	CacheStatsGet(context.Context, string) (ImplResponse, error)
+	JobsIdGet(context.Context, int32, string) (ImplResponse, error)
+	ReadyGet(context.Context) (ImplResponse, error)
 	UserBannerGet(context.Context, int32, int32, bool, string) (ImplResponse, error)
+	WarmUp(context.Context) error
 	Stop() error
 }
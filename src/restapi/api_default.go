@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -52,6 +53,21 @@ func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) Rou
 // Routes returns all the api routes for the DefaultAPIController
 func (c *DefaultAPIController) Routes() Routes {
 	return Routes{
+		"AuditGet": Route{
+			strings.ToUpper("Get"),
+			"/audit",
+			c.AuditGet,
+		},
+		"BannerBatchPost": Route{
+			strings.ToUpper("Post"),
+			"/banner/batch",
+			c.BannerBatchPost,
+		},
+		"BannerDelete": Route{
+			strings.ToUpper("Delete"),
+			"/banner",
+			c.BannerDelete,
+		},
 		"BannerGet": Route{
 			strings.ToUpper("Get"),
 			"/banner",
@@ -62,16 +78,56 @@ func (c *DefaultAPIController) Routes() Routes {
 			"/banner/{id}",
 			c.BannerIdDelete,
 		},
+		"BannerIdGet": Route{
+			strings.ToUpper("Get"),
+			"/banner/{id:-?[0-9]+}",
+			c.BannerIdGet,
+		},
 		"BannerIdPatch": Route{
 			strings.ToUpper("Patch"),
 			"/banner/{id}",
 			c.BannerIdPatch,
 		},
+		"BannerIdRestorePost": Route{
+			strings.ToUpper("Post"),
+			"/banner/{id}/restore",
+			c.BannerIdRestorePost,
+		},
+		"BannerIdVersionsGet": Route{
+			strings.ToUpper("Get"),
+			"/banner/{id}/versions",
+			c.BannerIdVersionsGet,
+		},
+		"BannerIdVersionsVersionRestorePost": Route{
+			strings.ToUpper("Post"),
+			"/banner/{id}/versions/{version}/restore",
+			c.BannerIdVersionsVersionRestorePost,
+		},
 		"BannerPost": Route{
 			strings.ToUpper("Post"),
 			"/banner",
 			c.BannerPost,
 		},
+		"BannerTrashGet": Route{
+			strings.ToUpper("Get"),
+			"/banner/trash",
+			c.BannerTrashGet,
+		},
+		"CacheStatsGet": Route{
+			strings.ToUpper("Get"),
+			"/cache/stats",
+			c.CacheStatsGet,
+		},
+		"JobsIdGet": Route{
+			strings.ToUpper("Get"),
+			"/jobs/{id}",
+			c.JobsIdGet,
+		},
+		"ReadyGet": Route{
+			strings.ToUpper("Get"),
+			"/ready",
+			c.ReadyGet,
+		},
 		"UserBannerGet": Route{
 			strings.ToUpper("Get"),
 			"/user_banner",
@@ -80,8 +136,122 @@ func (c *DefaultAPIController) Routes() Routes {
 	}
 }
 
-// BannerGet - Получение всех баннеров c фильтрацией по фиче и/или тегу
-func (c *DefaultAPIController) BannerGet(w http.ResponseWriter, r *http.Request) {
+// AuditGet - Получение журнала изменений баннеров
+func (c *DefaultAPIController) AuditGet(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	var bannerIdParam int32
+	if query.Has("banner_id") {
+		param, err := parseNumericParameter[int32](
+			query.Get("banner_id"),
+			WithParse[int32](parseInt32),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		bannerIdParam = param
+	}
+	actorParam := query.Get("actor")
+	var fromParam time.Time
+	if query.Has("from") {
+		param, err := parseTime(query.Get("from"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		fromParam = param
+	}
+	var toParam time.Time
+	if query.Has("to") {
+		param, err := parseTime(query.Get("to"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		toParam = param
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := parseNumericParameter[int32](
+			query.Get("limit"),
+			WithParse[int32](parseInt32),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	}
+	var offsetParam int32
+	if query.Has("offset") {
+		param, err := parseNumericParameter[int32](
+			query.Get("offset"),
+			WithParse[int32](parseInt32),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		offsetParam = param
+	}
+	result, err := c.service.AuditGet(r.Context(), tokenParam, bannerIdParam, actorParam, fromParam, toParam, limitParam, offsetParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerBatchPost - Создание нескольких баннеров
+func (c *DefaultAPIController) BannerBatchPost(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	bannerGetRequestParam := []models.BannerGetRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&bannerGetRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	for _, el := range bannerGetRequestParam {
+		if err := models.AssertBannerGetRequestRequired(el); err != nil {
+			c.errorHandler(w, r, err, nil)
+			return
+		}
+		if err := models.AssertBannerGetRequestConstraints(el); err != nil {
+			c.errorHandler(w, r, err, nil)
+			return
+		}
+	}
+	modeParam := query.Get("mode")
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.BannerBatchPost(r.Context(), bannerGetRequestParam, modeParam, tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerDelete - Фоновое удаление баннеров по фиче и/или тегу
+func (c *DefaultAPIController) BannerDelete(w http.ResponseWriter, r *http.Request) {
 	query, err := parseQuery(r.URL.RawQuery)
 	if err != nil {
 		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
@@ -100,7 +270,6 @@ func (c *DefaultAPIController) BannerGet(w http.ResponseWriter, r *http.Request)
 		}
 
 		featureIdParam = param
-	} else {
 	}
 	var tagIdParam int32
 	if query.Has("tag_id") {
@@ -114,7 +283,109 @@ func (c *DefaultAPIController) BannerGet(w http.ResponseWriter, r *http.Request)
 		}
 
 		tagIdParam = param
-	} else {
+	}
+	result, err := c.service.BannerDelete(r.Context(), tokenParam, featureIdParam, tagIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerGet - Получение всех баннеров c фильтрацией по фиче и/или тегу
+func (c *DefaultAPIController) BannerGet(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	// feature_id и tag_id можно передать списком через запятую или повторить параметр
+	featureIdsParam, err := parseNumericArrayParameter[int32](
+		strings.Join(query["feature_id"], ","), ",", false,
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tagIdsParam, err := parseNumericArrayParameter[int32](
+		strings.Join(query["tag_id"], ","), ",", false,
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var isActiveParam *bool
+	if query.Has("is_active") {
+		param, err := parseBoolParameter(
+			query.Get("is_active"),
+			WithRequire[bool](parseBool),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		isActiveParam = &param
+	}
+	createdFromParam, err := parseTime(query.Get("created_from"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	createdToParam, err := parseTime(query.Get("created_to"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	updatedFromParam, err := parseTime(query.Get("updated_from"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	updatedToParam, err := parseTime(query.Get("updated_to"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var contentParam models.JSONMap
+	if query.Has("content") {
+		param, err := parseJSONObject(query.Get("content"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		contentParam = param
+	}
+	contentFieldsParam, err := parseContentFields(query)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var cursorParam *models.BannerCursor
+	if query.Has("cursor") {
+		param, err := models.ParseBannerCursor(query.Get("cursor"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		cursorParam = &param
+	}
+	var sortParam *models.BannerSort
+	if query.Has("sort") {
+		param, err := models.ParseBannerSort(query.Get("sort"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		sortParam = &param
 	}
 	var limitParam int32
 	if query.Has("limit") {
@@ -144,14 +415,14 @@ func (c *DefaultAPIController) BannerGet(w http.ResponseWriter, r *http.Request)
 		offsetParam = param
 	} else {
 	}
-	result, err := c.service.BannerGet(r.Context(), tokenParam, featureIdParam, tagIdParam, limitParam, offsetParam)
+	result, err := c.service.BannerGet(r.Context(), tokenParam, featureIdsParam, tagIdsParam, isActiveParam, createdFromParam, createdToParam, updatedFromParam, updatedToParam, contentParam, contentFieldsParam, sortParam, cursorParam, limitParam, offsetParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	EncodeJSONResponse(result.Body, &result.Code, w)
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // BannerIdDelete - Удаление баннера по идентификатору
@@ -166,14 +437,37 @@ func (c *DefaultAPIController) BannerIdDelete(w http.ResponseWriter, r *http.Req
 		return
 	}
 	tokenParam := r.Header.Get("token")
-	result, err := c.service.BannerIdDelete(r.Context(), idParam, tokenParam)
+	ifMatchParam := r.Header.Get("If-Match")
+	result, err := c.service.BannerIdDelete(r.Context(), idParam, tokenParam, ifMatchParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	EncodeJSONResponse(result.Body, &result.Code, w)
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerIdGet - Получение баннера по идентификатору
+func (c *DefaultAPIController) BannerIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := parseNumericParameter[int32](
+		params["id"],
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.BannerIdGet(r.Context(), idParam, tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // BannerIdPatch - Обновление содержимого баннера
@@ -203,14 +497,89 @@ func (c *DefaultAPIController) BannerIdPatch(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	tokenParam := r.Header.Get("token")
-	result, err := c.service.BannerIdPatch(r.Context(), idParam, bannerIdDeleteRequestParam, tokenParam)
+	ifMatchParam := r.Header.Get("If-Match")
+	result, err := c.service.BannerIdPatch(r.Context(), idParam, bannerIdDeleteRequestParam, tokenParam, ifMatchParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerIdRestorePost - Восстановление удаленного баннера
+func (c *DefaultAPIController) BannerIdRestorePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := parseNumericParameter[int32](
+		params["id"],
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.BannerIdRestorePost(r.Context(), idParam, tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerIdVersionsGet - Получение истории версий баннера
+func (c *DefaultAPIController) BannerIdVersionsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := parseNumericParameter[int32](
+		params["id"],
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.BannerIdVersionsGet(r.Context(), idParam, tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerIdVersionsVersionRestorePost - Восстановление версии баннера
+func (c *DefaultAPIController) BannerIdVersionsVersionRestorePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := parseNumericParameter[int32](
+		params["id"],
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	versionParam, err := parseNumericParameter[int32](
+		params["version"],
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.BannerIdVersionsVersionRestorePost(r.Context(), idParam, versionParam, tokenParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	EncodeJSONResponse(result.Body, &result.Code, w)
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // BannerPost - Создание нового баннера
@@ -238,7 +607,98 @@ func (c *DefaultAPIController) BannerPost(w http.ResponseWriter, r *http.Request
 		return
 	}
 	// If no error, encode the body and the result code
-	EncodeJSONResponse(result.Body, &result.Code, w)
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// BannerTrashGet - Получение удаленных баннеров
+func (c *DefaultAPIController) BannerTrashGet(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := parseNumericParameter[int32](
+			query.Get("limit"),
+			WithParse[int32](parseInt32),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	}
+	var offsetParam int32
+	if query.Has("offset") {
+		param, err := parseNumericParameter[int32](
+			query.Get("offset"),
+			WithParse[int32](parseInt32),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+
+		offsetParam = param
+	}
+	result, err := c.service.BannerTrashGet(r.Context(), tokenParam, limitParam, offsetParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// CacheStatsGet - Получение статистики кэша
+func (c *DefaultAPIController) CacheStatsGet(w http.ResponseWriter, r *http.Request) {
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.CacheStatsGet(r.Context(), tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// JobsIdGet - Получение состояния фоновой задачи
+func (c *DefaultAPIController) JobsIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := parseNumericParameter[int32](
+		params["id"],
+		WithRequire[int32](parseInt32),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tokenParam := r.Header.Get("token")
+	result, err := c.service.JobsIdGet(r.Context(), idParam, tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ReadyGet - Проверка готовности сервиса
+func (c *DefaultAPIController) ReadyGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.ReadyGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // UserBannerGet - Получение баннера для пользователя
@@ -304,5 +764,5 @@ func (c *DefaultAPIController) UserBannerGet(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	// If no error, encode the body and the result code
-	EncodeJSONResponse(result.Body, &result.Code, w)
+	EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
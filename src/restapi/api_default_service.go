@@ -5,6 +5,25 @@ import (
 	"banner/internal/storage"
 	"banner/models"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPageLimit - размер страницы GET /banner при выводе по курсору, если limit не задан
+const defaultPageLimit = 100
+
+// maxBatchSize - наибольшее количество баннеров в одном запросе POST /banner/batch
+const maxBatchSize = 1000
+
+// Режимы POST /banner/batch: atomic - все баннеры создаются в одной транзакции или не создается ни один,
+// partial - каждый баннер создается отдельно
+const (
+	batchModeAtomic  = "atomic"
+	batchModePartial = "partial"
 )
 
 // DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
@@ -22,8 +41,8 @@ func NewDefaultAPIService() DefaultAPIServicer {
 	}
 }
 
-// BannerGet - Получение всех баннеров c фильтрацией по фиче и/или тегу
-func (s *DefaultAPIService) BannerGet(ctx context.Context, token string, featureId int32, tagId int32, limit int32, offset int32) (ImplResponse, error) {
+// AuditGet - Получение журнала изменений баннеров
+func (s *DefaultAPIService) AuditGet(ctx context.Context, token string, bannerId int32, actor string, from time.Time, to time.Time, limit int32, offset int32) (ImplResponse, error) {
 	ok, err := simple_auth.CheckAdminToken(token)
 	if err != nil {
 		return Response(401, "Пользователь не авторизован"), nil
@@ -31,15 +50,256 @@ func (s *DefaultAPIService) BannerGet(ctx context.Context, token string, feature
 	if !ok {
 		return Response(403, "Пользователь не имеет доступа"), nil
 	}
-	res, err := s.Storage.GetMany(featureId, tagId, limit, offset)
+	if bannerId < 0 || limit < 0 || offset < 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id, limit и offset не могут быть отрицательными"}), nil
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Конец периода должен быть позже начала"}), nil
+	}
+	res, err := s.Storage.Audit(models.AuditFilter{
+		BannerId: bannerId,
+		Actor:    actor,
+		From:     from,
+		To:       to,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
 	if err != nil {
-		return Response(500, err.Error()), nil
+		return Response(500, "Внутренняя ошибка сервера"), nil
 	}
 	return Response(200, res), nil
 }
 
+// BannerBatchPost - Создание нескольких баннеров. Сначала проверяются все баннеры, затем создаются в режиме mode
+// (по умолчанию atomic). Для каждого баннера возвращается идентификатор или причина, по которой он не создан
+func (s *DefaultAPIService) BannerBatchPost(ctx context.Context, bannerGetRequest []models.BannerGetRequest, mode string, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if mode == "" {
+		mode = batchModeAtomic
+	}
+	if mode != batchModeAtomic && mode != batchModePartial {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Параметр mode должен быть atomic или partial"}), nil
+	}
+	if len(bannerGetRequest) == 0 || len(bannerGetRequest) > maxBatchSize {
+		return Response(400, models.UserBannerGet400Response{Error: fmt.Sprintf("Некорректные данные. Пакет должен содержать от 1 до %d баннеров", maxBatchSize)}), nil
+	}
+	results := make([]models.BannerBatchPost201ResponseInner, len(bannerGetRequest))
+	records := make([]*models.InsertData, 0, len(bannerGetRequest))
+	// indexes - номера в запросе баннеров, прошедших проверку (в порядке records)
+	indexes := make([]int, 0, len(bannerGetRequest))
+	pairs := make(map[[2]int32]int)
+	invalid := false
+	for i, banner := range bannerGetRequest {
+		results[i].Index = int32(i)
+		msg := validateBanner(banner)
+		for k, tag := range banner.TagIds {
+			if msg != "" {
+				break
+			}
+			if slices.Contains(banner.TagIds[:k], tag) {
+				msg = fmt.Sprintf("Некорректные данные. Тэг %d повторяется в баннере", tag)
+			} else if j, ok := pairs[[2]int32{banner.FeatureId, tag}]; ok {
+				msg = fmt.Sprintf("Некорректные данные. Пара фича-тэг уже используется баннером %d пакета", j)
+			}
+		}
+		if msg != "" {
+			results[i].Error = msg
+			invalid = true
+			continue
+		}
+		for _, tag := range banner.TagIds {
+			pairs[[2]int32{banner.FeatureId, tag}] = i
+		}
+		records = append(records, &models.InsertData{
+			Feature:   banner.FeatureId,
+			TagIds:    banner.TagIds,
+			Content:   banner.Content,
+			IsActive:  banner.IsActive,
+			StartsAt:  banner.StartsAt,
+			EndsAt:    banner.EndsAt,
+			AuditInfo: auditInfo(ctx, token),
+		})
+		indexes = append(indexes, i)
+	}
+	if invalid && mode == batchModeAtomic {
+		return Response(400, results), nil
+	}
+	ids, errs := s.Storage.InsertBatch(records, mode == batchModeAtomic)
+	code := 201
+	for k, i := range indexes {
+		switch {
+		case errs[k] == nil:
+			results[i].BannerId = ids[k]
+		case errors.Is(errs[k], storage.ErrUnavailable):
+			results[i].Error = "База данных недоступна, попробуйте позже"
+		case errors.Is(errs[k], storage.ErrBatchAborted):
+			results[i].Error = "Баннер не создан из-за ошибки в другом баннере пакета"
+		case errors.Is(errs[k], storage.ErrConflict):
+			results[i].Error = "Пара фича-тэг баннера уже занята другим баннером"
+		default:
+			results[i].Error = "Внутренняя ошибка сервера"
+		}
+		if mode == batchModeAtomic && errs[k] != nil && !errors.Is(errs[k], storage.ErrBatchAborted) {
+			code = batchErrorCode(errs[k])
+		}
+	}
+	if mode == batchModePartial && (invalid || slices.ContainsFunc(errs, func(err error) bool { return err != nil })) {
+		code = 200
+	}
+	return Response(code, results), nil
+}
+
+// batchErrorCode возвращает код ответа атомарного пакета, не созданного из-за ошибки err
+func batchErrorCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrUnavailable):
+		return 503
+	case errors.Is(err, storage.ErrConflict):
+		return 409
+	default:
+		return 500
+	}
+}
+
+// validateBanner проверяет данные нового баннера так же, как POST /banner. Возвращает описание ошибки
+// или пустую строку, если данные корректны
+func validateBanner(banner models.BannerGetRequest) string {
+	if banner.FeatureId <= 0 || slices.ContainsFunc(banner.TagIds, func(tag int32) bool { return tag <= 0 }) {
+		return "Некорректные данные. Фича и тэг должны быть положительными числами"
+	}
+	if len(banner.TagIds) == 0 {
+		return "Некорректные данные. Необходимо указать хотя бы один тэг"
+	}
+	if !models.ValidWindow(banner.StartsAt, banner.EndsAt) {
+		return "Некорректные данные. Окончание показа должно быть позже начала"
+	}
+	return ""
+}
+
+// BannerDelete - Фоновое удаление баннеров по фиче и/или тегу
+func (s *DefaultAPIService) BannerDelete(ctx context.Context, token string, featureId int32, tagId int32) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if featureId < 0 || tagId < 0 || (featureId == 0 && tagId == 0) {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Необходимо указать фичу и/или тэг положительными числами"}), nil
+	}
+	jobId, err := s.Storage.DeleteMany(featureId, tagId, auditInfo(ctx, token))
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	return Response(202, models.BannerDelete202Response{JobId: jobId}), nil
+}
+
+// BannerGet - Получение всех баннеров c фильтрацией по фичам, тегам, активности, датам создания и изменения
+// и содержимому в порядке sort (по умолчанию по id). Если задан cursor, баннеры выводятся страницами по limit
+// (по умолчанию defaultPageLimit, порядок по умолчанию - по updated_at) вместе с курсором следующей страницы.
+// Количество подходящих баннеров возвращается в заголовке X-Total-Count
+func (s *DefaultAPIService) BannerGet(ctx context.Context, token string, featureIds []int32, tagIds []int32, isActive *bool, createdFrom time.Time, createdTo time.Time, updatedFrom time.Time, updatedTo time.Time, content models.JSONMap, contentFields map[string]string, sort *models.BannerSort, cursor *models.BannerCursor, limit int32, offset int32) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if limit < 0 || offset < 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Параметры limit и offset не могут быть отрицательными"}), nil
+	}
+	if afterEnd(createdFrom, createdTo) || afterEnd(updatedFrom, updatedTo) {
+		return Response(400, models.UserBannerGet400Response{Error: "Начало периода позже его окончания"}), nil
+	}
+	filter := models.BannerFilter{
+		FeatureIds:      featureIds,
+		TagIds:          tagIds,
+		IsActive:        isActive,
+		CreatedFrom:     createdFrom,
+		CreatedTo:       createdTo,
+		UpdatedFrom:     updatedFrom,
+		UpdatedTo:       updatedTo,
+		ContentFields:   contentFields,
+		ContentContains: content,
+		Limit:           limit,
+		Offset:          offset,
+	}
+	if cursor == nil {
+		if sort != nil {
+			filter.Sort = *sort
+		}
+		res, total, err := s.Storage.GetMany(filter)
+		if errors.Is(err, storage.ErrUnavailable) {
+			return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+		}
+		if err != nil {
+			return Response(500, "Внутренняя ошибка сервера"), nil
+		}
+		return ResponseWithHeaders(200, totalHeader(total), res), nil
+	}
+	if offset > 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Параметры cursor и offset несовместимы"}), nil
+	}
+	filter.Sort = models.BannerSort{Field: models.SortByUpdatedAt}
+	if sort != nil {
+		filter.Sort = *sort
+	}
+	// курсоры без поля сортировки выданы до появления параметра sort и относятся к порядку по updated_at
+	if cursorSort := cursor.Sort; !cursor.First() {
+		if cursorSort == "" {
+			cursorSort = models.SortByUpdatedAt
+		}
+		if cursorSort != filter.Sort.String() {
+			return Response(400, models.UserBannerGet400Response{Error: "Курсор получен для другого порядка сортировки"}), nil
+		}
+	}
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	// запрашиваем на один баннер больше, чтобы узнать, есть ли следующая страница
+	filter.After, filter.Limit = cursor, limit+1
+	res, total, err := s.Storage.GetMany(filter)
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	page := models.BannerGet200Response{Banners: res, Total: total}
+	if len(res) > int(limit) {
+		page.Banners = res[:limit]
+		next := models.BannerCursor{Sort: filter.Sort.String(), BannerKey: filter.Sort.Key(page.Banners[limit-1])}
+		page.NextCursor = next.String()
+	}
+	return ResponseWithHeaders(200, totalHeader(total), page), nil
+}
+
+// afterEnd сообщает, что заданы обе границы периода и начало позже окончания
+func afterEnd(from, to time.Time) bool {
+	return !from.IsZero() && !to.IsZero() && from.After(to)
+}
+
+// totalHeader - заголовок с количеством баннеров, подходящих под фильтры
+func totalHeader(total int64) map[string][]string {
+	return map[string][]string{"X-Total-Count": {strconv.FormatInt(total, 10)}}
+}
+
 // BannerIdDelete - Удаление баннера по идентификатору
-func (s *DefaultAPIService) BannerIdDelete(ctx context.Context, id int32, token string) (ImplResponse, error) {
+func (s *DefaultAPIService) BannerIdDelete(ctx context.Context, id int32, token string, ifMatch string) (ImplResponse, error) {
 	ok, err := simple_auth.CheckAdminToken(token)
 	if err != nil {
 		return Response(401, "Пользователь не авторизован"), nil
@@ -50,7 +310,20 @@ func (s *DefaultAPIService) BannerIdDelete(ctx context.Context, id int32, token
 	if !ok {
 		return Response(403, "Пользователь не имеет доступа"), nil
 	}
-	found, err := s.Storage.Delete(id)
+	expectedVersion, err := parseIfMatch(ifMatch)
+	if errors.Is(err, errWeakETag) {
+		return Response(412, models.UserBannerGet400Response{Error: "If-Match требует сильный ETag"}), nil
+	}
+	if err != nil {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректный заголовок If-Match"}), nil
+	}
+	found, err := s.Storage.Delete(id, expectedVersion, auditInfo(ctx, token))
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if errors.Is(err, storage.ErrVersionMismatch) {
+		return Response(412, models.UserBannerGet400Response{Error: "Баннер был изменен другим пользователем"}), nil
+	}
 	if err != nil {
 		return Response(500, "Внутренняя ошибка сервера"), nil
 	}
@@ -61,8 +334,33 @@ func (s *DefaultAPIService) BannerIdDelete(ctx context.Context, id int32, token
 
 }
 
+// BannerIdGet - Получение баннера по идентификатору
+func (s *DefaultAPIService) BannerIdGet(ctx context.Context, id int32, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if id <= 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id должен быть положительным числом"}), nil
+	}
+	res, found, err := s.Storage.GetById(id)
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	if !found {
+		return Response(404, "Баннер не найден"), nil
+	}
+	return ResponseWithHeaders(200, map[string][]string{"ETag": {formatETag(res.Version)}}, res), nil
+}
+
 // BannerIdPatch - Обновление содержимого баннера
-func (s *DefaultAPIService) BannerIdPatch(ctx context.Context, id int32, bannerIdDeleteRequest models.BannerIdDeleteRequest, token string) (ImplResponse, error) {
+func (s *DefaultAPIService) BannerIdPatch(ctx context.Context, id int32, bannerIdDeleteRequest models.BannerIdDeleteRequest, token string, ifMatch string) (ImplResponse, error) {
 	ok, err := simple_auth.CheckAdminToken(token)
 	if err != nil {
 		return Response(401, "Пользователь не авторизован"), nil
@@ -73,7 +371,14 @@ func (s *DefaultAPIService) BannerIdPatch(ctx context.Context, id int32, bannerI
 	if id <= 0 {
 		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id должен быть положительным числом"}), nil
 	}
-	toUpdate := models.InsertData{}
+	expectedVersion, err := parseIfMatch(ifMatch)
+	if errors.Is(err, errWeakETag) {
+		return Response(412, models.UserBannerGet400Response{Error: "If-Match требует сильный ETag"}), nil
+	}
+	if err != nil {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректный заголовок If-Match"}), nil
+	}
+	toUpdate := models.InsertData{ExpectedVersion: expectedVersion}
 	if bannerIdDeleteRequest.FeatureId != nil {
 		if *bannerIdDeleteRequest.FeatureId <= 0 {
 			return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Feature должен быть положительным числом"}), nil
@@ -92,14 +397,114 @@ func (s *DefaultAPIService) BannerIdPatch(ctx context.Context, id int32, bannerI
 		toUpdate.Content = *bannerIdDeleteRequest.Content
 	}
 	if bannerIdDeleteRequest.IsActive != nil {
-		toUpdate.IsActive = *bannerIdDeleteRequest.IsActive
+		toUpdate.IsActive, toUpdate.SetIsActive = *bannerIdDeleteRequest.IsActive, true
+	}
+	if !models.ValidWindow(bannerIdDeleteRequest.StartsAt.Value, bannerIdDeleteRequest.EndsAt.Value) {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Окончание показа должно быть позже начала"}), nil
+	}
+	// явный null снимает границу окна показа
+	toUpdate.StartsAt, toUpdate.ClearStartsAt = bannerIdDeleteRequest.StartsAt.Value, bannerIdDeleteRequest.StartsAt.Set && bannerIdDeleteRequest.StartsAt.Value == nil
+	toUpdate.EndsAt, toUpdate.ClearEndsAt = bannerIdDeleteRequest.EndsAt.Value, bannerIdDeleteRequest.EndsAt.Set && bannerIdDeleteRequest.EndsAt.Value == nil
+	toUpdate.AuditInfo = auditInfo(ctx, token)
+	version, found, err := s.Storage.Update(id, &toUpdate)
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if errors.Is(err, storage.ErrInvalidWindow) {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Окончание показа должно быть позже начала"}), nil
+	}
+	if errors.Is(err, storage.ErrVersionMismatch) {
+		return Response(412, models.UserBannerGet400Response{Error: "Баннер был изменен другим пользователем"}), nil
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		return Response(409, models.UserBannerGet400Response{Error: "Пара фича-тэг баннера уже занята другим баннером"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
 	}
-	found, err := s.Storage.Update(id, &toUpdate)
 	if !found {
 		return Response(404, "Баннер не найден"), nil
 	}
+	return ResponseWithHeaders(200, map[string][]string{"ETag": {formatETag(version)}}, nil), nil
+}
+
+// BannerIdRestorePost - Восстановление удаленного баннера
+func (s *DefaultAPIService) BannerIdRestorePost(ctx context.Context, id int32, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if id <= 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id должен быть положительным числом"}), nil
+	}
+	found, err := s.Storage.Restore(id, auditInfo(ctx, token))
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		return Response(409, models.UserBannerGet400Response{Error: "Пара фича-тэг баннера уже занята другим баннером"}), nil
+	}
 	if err != nil {
-		return Response(500, err.Error()), nil
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	if !found {
+		return Response(404, "Удаленный баннер не найден"), nil
+	}
+	return Response(200, nil), nil
+}
+
+// BannerIdVersionsGet - Получение истории версий баннера
+func (s *DefaultAPIService) BannerIdVersionsGet(ctx context.Context, id int32, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if id <= 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id должен быть положительным числом"}), nil
+	}
+	res, found, err := s.Storage.Versions(id)
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	if !found {
+		return Response(404, "Баннер не найден"), nil
+	}
+	return Response(200, res), nil
+}
+
+// BannerIdVersionsVersionRestorePost - Восстановление версии баннера
+func (s *DefaultAPIService) BannerIdVersionsVersionRestorePost(ctx context.Context, id int32, version int32, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if id <= 0 || version <= 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id и версия должны быть положительными числами"}), nil
+	}
+	found, err := s.Storage.RestoreVersion(id, version, auditInfo(ctx, token))
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		return Response(409, models.UserBannerGet400Response{Error: "Пара фича-тэг баннера уже занята другим баннером"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	if !found {
+		return Response(404, "Версия баннера не найдена"), nil
 	}
 	return Response(200, nil), nil
 }
@@ -116,24 +521,96 @@ func (s *DefaultAPIService) BannerPost(ctx context.Context, bannerGetRequest mod
 	if bannerGetRequest.FeatureId <= 0 {
 		return Response(400, "Некорректные данные. Фича и тэг должны быть положительными числами"), nil
 	}
+	if len(bannerGetRequest.TagIds) == 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Необходимо указать хотя бы один тэг"}), nil
+	}
 	for _, i := range bannerGetRequest.TagIds {
 		if i <= 0 {
 			return Response(400, "Некорректные данные. Фича и тэг должны быть положительными числами"), nil
 		}
 	}
+	if !models.ValidWindow(bannerGetRequest.StartsAt, bannerGetRequest.EndsAt) {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Окончание показа должно быть позже начала"}), nil
+	}
 	id, err := s.Storage.Insert(&models.InsertData{
-		Feature:  bannerGetRequest.FeatureId,
-		TagIds:   bannerGetRequest.TagIds,
-		Content:  bannerGetRequest.Content,
-		IsActive: bannerGetRequest.IsActive,
+		Feature:   bannerGetRequest.FeatureId,
+		TagIds:    bannerGetRequest.TagIds,
+		Content:   bannerGetRequest.Content,
+		IsActive:  bannerGetRequest.IsActive,
+		StartsAt:  bannerGetRequest.StartsAt,
+		EndsAt:    bannerGetRequest.EndsAt,
+		AuditInfo: auditInfo(ctx, token),
 	})
-
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Пара фича-тэг баннера уже занята другим баннером"}), nil
+	}
 	if err != nil {
-		return Response(400, models.UserBannerGet400Response{Error: err.Error()}), nil
+		return Response(500, "Внутренняя ошибка сервера"), nil
 	}
 	return Response(201, models.BannerGet201Response{BannerId: id}), nil
 }
 
+// BannerTrashGet - Получение удаленных баннеров
+func (s *DefaultAPIService) BannerTrashGet(ctx context.Context, token string, limit int32, offset int32) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	res, err := s.Storage.Trash(limit, offset)
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
+	if err != nil {
+		return Response(500, "Внутренняя ошибка сервера"), nil
+	}
+	return Response(200, res), nil
+}
+
+// CacheStatsGet - Получение статистики кэша
+func (s *DefaultAPIService) CacheStatsGet(ctx context.Context, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	return Response(200, s.Storage.CacheStats()), nil
+}
+
+// JobsIdGet - Получение состояния фоновой задачи
+func (s *DefaultAPIService) JobsIdGet(ctx context.Context, id int32, token string) (ImplResponse, error) {
+	ok, err := simple_auth.CheckAdminToken(token)
+	if err != nil {
+		return Response(401, "Пользователь не авторизован"), nil
+	}
+	if !ok {
+		return Response(403, "Пользователь не имеет доступа"), nil
+	}
+	if id <= 0 {
+		return Response(400, models.UserBannerGet400Response{Error: "Некорректные данные. Id должен быть положительным числом"}), nil
+	}
+	job, found := s.Storage.Job(id)
+	if !found {
+		return Response(404, "Задача не найдена"), nil
+	}
+	return Response(200, job), nil
+}
+
+// ReadyGet - Проверка готовности сервиса
+func (s *DefaultAPIService) ReadyGet(ctx context.Context) (ImplResponse, error) {
+	if !s.Storage.Ready() {
+		return Response(503, models.ReadyGet200Response{Status: "warming_up"}), nil
+	}
+	return Response(200, models.ReadyGet200Response{Status: "ready"}), nil
+}
+
 // UserBannerGet - Получение баннера для пользователя
 func (s *DefaultAPIService) UserBannerGet(ctx context.Context, tagId int32, featureId int32, useLastRevision bool, token string) (ImplResponse, error) {
 	// Add api_default_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
@@ -144,9 +621,12 @@ func (s *DefaultAPIService) UserBannerGet(ctx context.Context, tagId int32, feat
 	if tagId <= 0 || featureId <= 0 {
 		return Response(400, "Некорректные данные. Фича и тэг должны быть положительными числами"), nil
 	}
-	res, userAccess, found, err := s.Storage.GetUserBanner(featureId, tagId, useLastRevision)
+	res, userAccess, found, err := s.Storage.GetUserBanner(ctx, featureId, tagId, useLastRevision)
+	if errors.Is(err, storage.ErrUnavailable) {
+		return Response(503, models.UserBannerGet400Response{Error: "База данных недоступна, попробуйте позже"}), nil
+	}
 	if err != nil {
-		return Response(500, "Внутренняя ошибка сервера: "+err.Error()), nil
+		return Response(500, "Внутренняя ошибка сервера"), nil
 	}
 	if !found {
 		return Response(404, "Баннер не найден"), nil
@@ -160,6 +640,44 @@ func (s *DefaultAPIService) UserBannerGet(ctx context.Context, tagId int32, feat
 	return Response(200, res), nil
 }
 
+// auditInfo возвращает автора изменения и идентификатор запроса для журнала изменений
+func auditInfo(ctx context.Context, token string) models.AuditInfo {
+	return models.AuditInfo{
+		Actor:     simple_auth.GetActor(token),
+		RequestId: RequestIdFromContext(ctx),
+	}
+}
+
+// formatETag возвращает ETag для версии баннера
+func formatETag(version int32) string {
+	return `"` + strconv.Itoa(int(version)) + `"`
+}
+
+// errWeakETag возвращается для слабого ETag в If-Match: RFC 7232 требует для If-Match строгого сравнения,
+// которому слабый ETag не соответствует
+var errWeakETag = errors.New("weak ETag in If-Match header")
+
+// parseIfMatch возвращает версию баннера из заголовка If-Match. Пустой заголовок и * не требуют проверки версии
+func parseIfMatch(header string) (int32, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return 0, errWeakETag
+	}
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 32)
+	if err != nil || version <= 0 {
+		return 0, errors.New("invalid If-Match header: " + header)
+	}
+	return int32(version), nil
+}
+
+// WarmUp прогревает кэш. До его завершения ReadyGet отвечает 503
+func (s *DefaultAPIService) WarmUp(ctx context.Context) error {
+	return s.Storage.WarmUp(ctx)
+}
+
 func (s *DefaultAPIService) Stop() error {
 	return s.Storage.Stop()
 }
@@ -1,7 +1,8 @@
 package openapi
 
-// ImplResponse defines an implementation response with error code and the associated body
+// ImplResponse defines an implementation response with error code, headers and the associated body
 type ImplResponse struct {
-	Code int
-	Body interface{}
+	Code    int
+	Headers map[string][]string
+	Body    interface{}
 }
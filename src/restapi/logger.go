@@ -4,15 +4,29 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 )
 
+var (
+	requestLogOnce sync.Once
+	requestLog     *log.Logger
+)
+
+// requestLogger открывает файл LOG_PATH один раз на процесс, сколько бы маршрутизаторов ни создавалось
+func requestLogger() *log.Logger {
+	requestLogOnce.Do(func() {
+		logFile, err := os.OpenFile(os.Getenv("LOG_PATH"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatalf("failed to open log file: %v", err)
+		}
+		requestLog = log.New(logFile, "", log.LstdFlags)
+	})
+	return requestLog
+}
+
 func Logger(inner http.Handler, name string) http.Handler {
-	logFile, err := os.OpenFile(os.Getenv("LOG_PATH"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Fatalf("failed to open log file: %v", err)
-	}
-	logger := log.New(logFile, "", log.LstdFlags)
+	logger := requestLogger()
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+type requestIdKey struct{}
+
+// maxRequestIdLength - длина столбца request_id в журнале изменений
+const maxRequestIdLength = 64
+
+// RequestId берет идентификатор запроса из заголовка X-Request-Id (или создает новый, если заголовка нет
+// или он не подходит для журнала изменений), возвращает его в ответе и кладет в контекст запроса
+func RequestId(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if !validRequestId(id) {
+			id = newRequestId()
+		}
+		w.Header().Set("X-Request-Id", id)
+		inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIdKey{}, id)))
+	})
+}
+
+// RequestIdFromContext возвращает идентификатор запроса, сохраненный RequestId
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// validRequestId сообщает, что идентификатор из заголовка непустой, помещается в журнал изменений
+// и состоит только из видимых символов ASCII
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -10,6 +10,7 @@
 package openapi
 
 import (
+	"banner/models"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
@@ -18,6 +19,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -50,6 +52,7 @@ func NewRouter(routers ...Router) *mux.Router {
 			var handler http.Handler
 			handler = route.HandlerFunc
 			handler = Logger(handler, name)
+			handler = RequestId(handler)
 
 			router.
 				Methods(route.Method).
@@ -63,8 +66,14 @@ func NewRouter(routers ...Router) *mux.Router {
 }
 
 // EncodeJSONResponse uses the json encoder to write an interface to the http response with an optional status code
-func EncodeJSONResponse(i interface{}, status *int, w http.ResponseWriter) error {
+// and optional headers
+func EncodeJSONResponse(i interface{}, status *int, headers map[string][]string, w http.ResponseWriter) error {
 	wHeader := w.Header()
+	for key, values := range headers {
+		for _, value := range values {
+			wHeader.Add(key, value)
+		}
+	}
 
 	f, ok := i.(*os.File)
 	if ok {
@@ -342,3 +351,28 @@ func parseNumericArrayParameter[T Number](param, delim string, required bool, fn
 func parseQuery(rawQuery string) (url.Values, error) {
 	return url.ParseQuery(rawQuery)
 }
+
+// parseJSONObject разбирает параметр с JSON-объектом, например content={"title":"Sale"}
+func parseJSONObject(param string) (models.JSONMap, error) {
+	var res models.JSONMap
+	if err := json.Unmarshal([]byte(param), &res); err != nil || res == nil {
+		return nil, errors.New("parameter must be a JSON object")
+	}
+	return res, nil
+}
+
+// parseContentFields собирает параметры вида content.<путь>=<значение> (content.title=Sale, content.meta.lang=ru)
+func parseContentFields(query url.Values) (map[string]string, error) {
+	res := make(map[string]string)
+	for key := range query {
+		path, ok := strings.CutPrefix(key, "content.")
+		if !ok {
+			continue
+		}
+		if slices.Contains(strings.Split(path, "."), "") {
+			return nil, errors.New("invalid content path: " + path)
+		}
+		res[path] = query.Get(key)
+	}
+	return res, nil
+}
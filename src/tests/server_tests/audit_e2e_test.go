@@ -0,0 +1,141 @@
+package server_tests
+
+import (
+	"banner/models"
+	"banner/tests/testserver"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestAudit200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /audit, status 200",
+	})
+	exp.GET("/audit").
+		WithQuery("actor", "admin").
+		WithQuery("from", time.Now().Add(-24*time.Hour).Format(time.RFC3339)).
+		WithQuery("limit", 10).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array()
+}
+
+func TestAudit200_Test_2(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /audit, status 200 (records of create, update and delete)",
+	})
+
+	created := exp.POST("/banner").
+		WithJSON(models.BannerGetRequest{TagIds: []int32{1}, FeatureId: 2000, Content: map[string]interface{}{"title": "created"}, IsActive: true}).
+		WithHeader("token", "admin_token").
+		WithHeader("X-Request-Id", "audit-create").
+		Expect().Status(http.StatusCreated)
+	created.Header("X-Request-Id").IsEqual("audit-create")
+	id := created.JSON().Object().Value("banner_id").Number().Raw()
+
+	content := map[string]interface{}{"title": "updated"}
+	exp.PATCH("/banner/{id}").WithPath("id", id).
+		WithJSON(models.BannerIdDeleteRequest{Content: &content}).
+		WithHeader("token", "admin_token").
+		WithHeader("X-Request-Id", "audit-update").
+		Expect().Status(http.StatusOK).
+		Header("X-Request-Id").IsEqual("audit-update")
+
+	exp.DELETE("/banner/{id}").WithPath("id", id).
+		WithHeader("token", "admin_token").
+		WithHeader("X-Request-Id", "audit-delete").
+		Expect().Status(http.StatusNoContent)
+
+	// записи возвращаются начиная с последней
+	records := exp.GET("/audit").
+		WithQuery("banner_id", id).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array()
+	records.Length().IsEqual(3)
+	for i, action := range []string{"delete", "update", "create"} {
+		record := records.Value(i).Object()
+		record.Value("banner_id").IsEqual(id)
+		record.Value("action").IsEqual(action)
+		record.Value("actor").IsEqual("admin")
+		record.Value("request_id").IsEqual("audit-" + action)
+	}
+	diff := records.Value(1).Object().Value("diff").Object()
+	diff.Keys().ContainsOnly("content")
+	diff.Value("content").Object().Value("before").Object().Value("title").IsEqual("created")
+	diff.Value("content").Object().Value("after").Object().Value("title").IsEqual("updated")
+	records.Value(0).Object().NotContainsKey("after").Value("before").Object().Value("feature_id").IsEqual(2000)
+	records.Value(2).Object().NotContainsKey("before").Value("after").Object().Value("tag_ids").IsEqual([]int32{1})
+}
+
+func TestAudit200_Test_3(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /audit, status 200 (oversized X-Request-Id is replaced)",
+	})
+
+	oversized := strings.Repeat("r", 65)
+	requestId := exp.POST("/banner").
+		WithJSON(models.BannerGetRequest{TagIds: []int32{1}, FeatureId: 2000, Content: map[string]interface{}{"title": "created"}, IsActive: true}).
+		WithHeader("token", "admin_token").
+		WithHeader("X-Request-Id", oversized).
+		Expect().Status(http.StatusCreated).
+		Header("X-Request-Id")
+	requestId.NotEqual(oversized)
+	requestId.Length().IsEqual(32)
+
+	exp.GET("/audit").
+		WithQuery("actor", "admin").
+		WithQuery("limit", 1).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array().
+		Value(0).Object().Value("request_id").IsEqual(requestId.Raw())
+}
+
+func TestAudit400_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /audit, status 400 (to before from)",
+	})
+	exp.GET("/audit").
+		WithQuery("from", time.Now().Format(time.RFC3339)).
+		WithQuery("to", time.Now().Add(-time.Hour).Format(time.RFC3339)).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestAudit401_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /audit, status 401 (wrong_token)",
+	})
+	exp.GET("/audit").
+		WithHeader("token", "wrong_token").
+		Expect().Status(http.StatusUnauthorized)
+}
+
+func TestAudit403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /audit, status 403 (user_token)",
+	})
+	exp.GET("/audit").
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
@@ -0,0 +1,144 @@
+package server_tests
+
+import (
+	"banner/models"
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+// batchBanner - баннер пакета с фичей feature и тэгами tags
+func batchBanner(feature int32, tags ...int32) models.BannerGetRequest {
+	return models.BannerGetRequest{
+		TagIds:    tags,
+		FeatureId: feature,
+		Content: map[string]interface{}{
+			"title": "record from batch E2E test",
+		},
+		IsActive: true,
+	}
+}
+
+func TestPostBannerBatch201_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/batch, status 201 (atomic)",
+	})
+
+	results := exp.POST("/banner/batch").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1, 2), batchBanner(2001, 1), batchBanner(2002, 1)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusCreated).JSON().Array()
+	results.Length().IsEqual(3)
+	for i, result := range results.Iter() {
+		result.Object().Value("index").IsEqual(i)
+		result.Object().ContainsKey("banner_id").NotContainsKey("error")
+	}
+
+	exp.GET("/user_banner").
+		WithQuery("feature_id", 2001).
+		WithQuery("tag_id", 1).
+		WithQuery("use_last_revision", true).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusOK).JSON().Object().Value("title").IsEqual("record from batch E2E test")
+}
+
+func TestPostBannerBatch409_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/batch, status 409 (atomic, used pair)",
+	})
+
+	results := exp.POST("/banner/batch").
+		WithQuery("mode", "atomic").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1), batchBanner(1, 1), batchBanner(2001, 1)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusConflict).JSON().Array()
+	results.Length().IsEqual(3)
+	for _, result := range results.Iter() {
+		result.Object().NotContainsKey("banner_id").Value("error").String().NotEmpty()
+	}
+	results.Value(1).Object().Value("error").IsEqual("Пара фича-тэг баннера уже занята другим баннером")
+
+	exp.GET("/user_banner").
+		WithQuery("feature_id", 2000).
+		WithQuery("tag_id", 1).
+		WithQuery("use_last_revision", true).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestPostBannerBatch200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/batch, status 200 (partial)",
+	})
+
+	results := exp.POST("/banner/batch").
+		WithQuery("mode", "partial").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1), batchBanner(0, 1), batchBanner(1, 1), batchBanner(2001, 1)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array()
+	results.Length().IsEqual(4)
+	results.Value(0).Object().ContainsKey("banner_id").NotContainsKey("error")
+	results.Value(1).Object().NotContainsKey("banner_id").Value("error").String().NotEmpty()
+	results.Value(2).Object().NotContainsKey("banner_id").Value("error").String().NotEmpty()
+	results.Value(3).Object().ContainsKey("banner_id").NotContainsKey("error")
+}
+
+func TestPostBannerBatch400_Test_2(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/batch, status 400 (invalid batch)",
+	})
+
+	results := exp.POST("/banner/batch").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1, 2), batchBanner(2000, 2)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest).JSON().Array()
+	results.Value(0).Object().NotContainsKey("error")
+	results.Value(1).Object().Value("error").String().NotEmpty()
+
+	// повторяющийся тэг внутри одного баннера отклоняется при проверке, до записи в базу
+	results = exp.POST("/banner/batch").
+		WithQuery("mode", "partial").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1, 1), batchBanner(2001, 1)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array()
+	results.Value(0).Object().NotContainsKey("banner_id").Value("error").IsEqual("Некорректные данные. Тэг 1 повторяется в баннере")
+	results.Value(1).Object().ContainsKey("banner_id").NotContainsKey("error")
+
+	exp.POST("/banner/batch").
+		WithJSON([]models.BannerGetRequest{}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+	exp.POST("/banner/batch").
+		WithQuery("mode", "best_effort").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestPostBannerBatch403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/batch, status 403 (user_token)",
+	})
+
+	exp.POST("/banner/batch").
+		WithJSON([]models.BannerGetRequest{batchBanner(2000, 1)}).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
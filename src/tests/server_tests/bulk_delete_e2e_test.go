@@ -0,0 +1,78 @@
+package server_tests
+
+import (
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestBulkDelete202_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "DELETE /banner, status 202",
+	})
+	jobId := exp.DELETE("/banner").
+		WithQuery("feature_id", 998).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusAccepted).
+		JSON().Object().Value("job_id").Number().Raw()
+
+	exp.GET("/jobs/{id}").WithPath("id", int(jobId)).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).
+		JSON().Object().ContainsKey("status")
+}
+
+func TestBulkDelete400_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "DELETE /banner, status 400 (no filters)",
+	})
+	exp.DELETE("/banner").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestBulkDelete401_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "DELETE /banner, status 401 (wrong_token)",
+	})
+	exp.DELETE("/banner").
+		WithQuery("feature_id", 998).
+		WithHeader("token", "wrong_token").
+		Expect().Status(http.StatusUnauthorized)
+}
+
+func TestBulkDelete403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "DELETE /banner, status 403 (user_token)",
+	})
+	exp.DELETE("/banner").
+		WithQuery("feature_id", 998).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestJobs404_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /jobs/{id}, status 404",
+	})
+	exp.GET("/jobs/{id}").WithPath("id", 100000000).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusNotFound)
+}
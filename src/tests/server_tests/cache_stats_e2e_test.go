@@ -0,0 +1,77 @@
+package server_tests
+
+import (
+	"banner/models"
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestCacheStats200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /cache/stats, status 200",
+	})
+	obj := exp.GET("/cache/stats").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Object()
+	obj.ContainsKey("hits").ContainsKey("misses").ContainsKey("deduplicated").
+		ContainsKey("stale").ContainsKey("refreshes").
+		ContainsKey("last_known").ContainsKey("breaker").
+		ContainsKey("entries").ContainsKey("bytes").ContainsKey("evictions").ContainsKey("expired")
+	obj.Value("negative").Object().ContainsKey("hits").ContainsKey("invalidations").ContainsKey("entries")
+}
+
+func TestCacheStats403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /cache/stats, status 403 (user_token)",
+	})
+	exp.GET("/cache/stats").
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
+
+// Ответ 404 кэшируется, но созданный для пары баннер сразу доступен пользователю
+func TestNegativeCache_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /user_banner, status 404 then 200 after POST /banner",
+	})
+	for i := 0; i < 2; i++ {
+		exp.GET("/user_banner").
+			WithQuery("feature_id", 5010).
+			WithQuery("tag_id", 11).
+			WithHeader("token", "user_token").
+			Expect().Status(http.StatusNotFound)
+	}
+	exp.GET("/cache/stats").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("negative").Object().Value("hits").Number().Gt(0)
+
+	exp.POST("/banner").WithJSON(models.BannerGetRequest{
+		TagIds:    []int32{11},
+		FeatureId: 5010,
+		Content: map[string]interface{}{
+			"title": "negative cache E2E test",
+		},
+		IsActive: true,
+	}).WithHeader("token", "admin_token").
+		Expect().Status(http.StatusCreated)
+
+	exp.GET("/user_banner").
+		WithQuery("feature_id", 5010).
+		WithQuery("tag_id", 11).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("title", "negative cache E2E test")
+}
@@ -1,14 +1,16 @@
 package server_tests
 
 import (
+	"banner/tests/testserver"
 	"github.com/gavv/httpexpect/v2"
 	"net/http"
 	"testing"
 )
 
 func TestDelete200_Test_1(t *testing.T) {
+	t.Parallel()
 	e := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "DELETE /banner/{id}, status 204",
 	})
@@ -18,8 +20,9 @@ func TestDelete200_Test_1(t *testing.T) {
 }
 
 func TestDelete400_Test_1(t *testing.T) {
+	t.Parallel()
 	e := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "DELETE /banner/{id}, status 400",
 	})
@@ -29,8 +32,9 @@ func TestDelete400_Test_1(t *testing.T) {
 }
 
 func TestDelete401_Test_1(t *testing.T) {
+	t.Parallel()
 	e := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "DELETE /banner/{id}, status 401 (wrong_token)",
 	})
@@ -40,8 +44,9 @@ func TestDelete401_Test_1(t *testing.T) {
 }
 
 func TestDelete403_Test_1(t *testing.T) {
+	t.Parallel()
 	e := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "DELETE /banner/{id}, status 401 (user_token)",
 	})
@@ -51,8 +56,9 @@ func TestDelete403_Test_1(t *testing.T) {
 }
 
 func TestDelete404_Test_1(t *testing.T) {
+	t.Parallel()
 	e := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "DELETE /banner/{id}, status 404 ",
 	})
@@ -60,3 +66,16 @@ func TestDelete404_Test_1(t *testing.T) {
 		WithHeader("token", "admin_token").
 		Expect().Status(http.StatusNotFound)
 }
+
+func TestDelete412_Test_1(t *testing.T) {
+	t.Parallel()
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "DELETE /banner/{id}, status 412 (stale If-Match)",
+	})
+	e.DELETE("/banner/{id}").WithPath("id", 6).
+		WithHeader("token", "admin_token").
+		WithHeader("If-Match", `"100000"`).
+		Expect().Status(http.StatusPreconditionFailed)
+}
@@ -0,0 +1,59 @@
+package server_tests
+
+import (
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestGetBanner200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}, status 200",
+	})
+	resp := exp.GET("/banner/{id}").WithPath("id", 5).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+	resp.Header("ETag").NotEmpty()
+	resp.JSON().Object().ContainsKey("version")
+}
+
+func TestGetBanner401_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}, status 401 (wrong_token)",
+	})
+	exp.GET("/banner/{id}").WithPath("id", 5).
+		WithHeader("token", "wrong_token").
+		Expect().Status(http.StatusUnauthorized)
+}
+
+func TestGetBanner403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}, status 403 (user_token)",
+	})
+	exp.GET("/banner/{id}").WithPath("id", 5).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestGetBanner404_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}, status 404",
+	})
+	exp.GET("/banner/{id}").WithPath("id", 100000000).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusNotFound)
+}
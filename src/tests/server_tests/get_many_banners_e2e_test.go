@@ -1,15 +1,18 @@
 package server_tests
 
 import (
+	"banner/tests/testserver"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 )
 
 func TestGetManyBanners200_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 200 (with tag only)",
 	})
@@ -21,8 +24,9 @@ func TestGetManyBanners200_Test_1(t *testing.T) {
 }
 
 func TestGetManyBanners200_Test_2(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 200 (with tag, limit)",
 	})
@@ -35,8 +39,9 @@ func TestGetManyBanners200_Test_2(t *testing.T) {
 }
 
 func TestGetManyBanners200_Test_3(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 200 (with tag, limit, offset)",
 	})
@@ -50,8 +55,9 @@ func TestGetManyBanners200_Test_3(t *testing.T) {
 }
 
 func TestGetManyBanners200_Test_4(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 200 (with feature only)",
 	})
@@ -63,8 +69,9 @@ func TestGetManyBanners200_Test_4(t *testing.T) {
 }
 
 func TestGetManyBanners200_Test_5(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 200 (with feature, limit)",
 	})
@@ -77,8 +84,9 @@ func TestGetManyBanners200_Test_5(t *testing.T) {
 }
 
 func TestGetManyBanners200_Test_6(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 200 (with feature, tag)",
 	})
@@ -91,8 +99,9 @@ func TestGetManyBanners200_Test_6(t *testing.T) {
 }
 
 func TestGetManyBanners400_Test_4(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 400 (wrong_token)",
 	})
@@ -105,8 +114,9 @@ func TestGetManyBanners400_Test_4(t *testing.T) {
 }
 
 func TestGetManyBanners403_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /banner, status 403 (user_token)",
 	})
@@ -117,3 +127,233 @@ func TestGetManyBanners403_Test_1(t *testing.T) {
 		WithHeader("token", "user_token").
 		Expect().Status(http.StatusForbidden)
 }
+
+func TestGetManyBanners200_Test_7(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 200 (with content field)",
+	})
+
+	exp.GET("/banner").
+		WithQuery("feature_id", 8).
+		WithQuery("content.title", "some_title111").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array().Length().IsEqual(1)
+	exp.GET("/banner").
+		WithQuery("content.title", "Sale").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array().Length().IsEqual(0)
+}
+
+func TestGetManyBanners200_Test_8(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 200 (with content containment, tag, limit)",
+	})
+
+	exp.GET("/banner").
+		WithQuery("tag_id", 8).
+		WithQuery("content", `{"url": "some_url"}`).
+		WithQuery("limit", 3).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array().Length().IsEqual(3)
+}
+
+func TestGetManyBanners400_Test_5(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 400 (invalid content filter)",
+	})
+
+	exp.GET("/banner").
+		WithQuery("content", "title").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+	exp.GET("/banner").
+		WithQuery("content..title", "Sale").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestGetManyBanners200_Test_9(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 200 (with cursor)",
+	})
+
+	seen := make(map[float64]bool)
+	cursor := ""
+	for _, size := range []int{400, 400, 200} {
+		page := exp.GET("/banner").
+			WithQuery("tag_id", 8).
+			WithQuery("cursor", cursor).
+			WithQuery("limit", 400).
+			WithHeader("token", "admin_token").
+			Expect().Status(http.StatusOK).JSON().Object()
+		banners := page.Value("banners").Array()
+		banners.Length().IsEqual(size)
+		for _, banner := range banners.Iter() {
+			id := banner.Object().Value("banner_id").Number().Raw()
+			if seen[id] {
+				t.Fatalf("banner %v is returned twice", id)
+			}
+			seen[id] = true
+		}
+		if size < 400 {
+			page.NotContainsKey("next_cursor")
+			break
+		}
+		cursor = page.Value("next_cursor").String().NotEmpty().Raw()
+	}
+}
+
+func TestGetManyBanners400_Test_6(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 400 (invalid cursor, cursor with offset)",
+	})
+
+	exp.GET("/banner").
+		WithQuery("cursor", "not a cursor").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+	exp.GET("/banner").
+		WithQuery("cursor", "").
+		WithQuery("offset", 10).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestGetManyBanners200_Test_10(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 200 (with several features, is_active, sort, total)",
+	})
+
+	resp := exp.GET("/banner").
+		WithQuery("feature_id", "1,2").
+		WithQuery("feature_id", 3).
+		WithQuery("tag_id", "4,11").
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+	resp.Header("X-Total-Count").IsEqual("3")
+	resp.JSON().Array().Length().IsEqual(3)
+
+	exp.GET("/banner").
+		WithQuery("is_active", false).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).
+		JSON().Array().Value(0).Object().Value("feature_id").IsEqual(testserver.Features)
+
+	resp = exp.GET("/banner").
+		WithQuery("sort", "-id").
+		WithQuery("limit", 2).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+	resp.Header("X-Total-Count").IsEqual("1000")
+	banners := resp.JSON().Array()
+	banners.Length().IsEqual(2)
+	banners.Value(0).Object().Value("feature_id").IsEqual(testserver.Features)
+	banners.Value(1).Object().Value("feature_id").IsEqual(testserver.Features - 1)
+}
+
+func TestGetManyBanners200_Test_11(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 200 (with created_at, updated_at ranges)",
+	})
+
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	exp.GET("/banner").
+		WithQuery("created_from", future).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array().Length().IsEqual(0)
+	exp.GET("/banner").
+		WithQuery("updated_to", future).
+		WithQuery("limit", 1).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).Header("X-Total-Count").IsEqual("1000")
+}
+
+func TestGetManyBanners200_Test_12(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 200 (with cursor, descending sort)",
+	})
+
+	next := float64(testserver.Features)
+	cursor := ""
+	for _, size := range []int{400, 400, 200} {
+		page := exp.GET("/banner").
+			WithQuery("sort", "-id").
+			WithQuery("cursor", cursor).
+			WithQuery("limit", 400).
+			WithHeader("token", "admin_token").
+			Expect().Status(http.StatusOK).JSON().Object()
+		page.Value("total").IsEqual(testserver.Features)
+		banners := page.Value("banners").Array()
+		banners.Length().IsEqual(size)
+		for _, banner := range banners.Iter() {
+			banner.Object().Value("feature_id").IsEqual(next)
+			next--
+		}
+		if size < 400 {
+			page.NotContainsKey("next_cursor")
+			break
+		}
+		cursor = page.Value("next_cursor").String().NotEmpty().Raw()
+	}
+}
+
+func TestGetManyBanners400_Test_7(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner, status 400 (invalid filters and sort)",
+	})
+
+	for _, query := range []map[string]interface{}{
+		{"sort": "title"},
+		{"is_active": "maybe"},
+		{"feature_id": "1,,2"},
+		{"created_from": "yesterday"},
+		{"updated_from": "2024-04-10T00:00:00Z", "updated_to": "2024-04-09T00:00:00Z"},
+		{"limit": -1},
+		{"offset": -1},
+	} {
+		req := exp.GET("/banner").WithHeader("token", "admin_token")
+		for name, value := range query {
+			req = req.WithQuery(name, value)
+		}
+		req.Expect().Status(http.StatusBadRequest)
+	}
+
+	cursor := exp.GET("/banner").
+		WithQuery("sort", "-id").
+		WithQuery("cursor", "").
+		WithQuery("limit", 1).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Object().Value("next_cursor").String().Raw()
+	exp.GET("/banner").
+		WithQuery("sort", "id").
+		WithQuery("cursor", cursor).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
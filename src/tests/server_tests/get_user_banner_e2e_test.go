@@ -1,8 +1,11 @@
 package server_tests
 
 import (
+	"banner/models"
+	"banner/tests/testserver"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 )
@@ -11,8 +14,9 @@ import (
 //feature = [1000]: ["is_active"] = false
 
 func TestGetUserBanner200_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 200",
 	})
@@ -26,8 +30,9 @@ func TestGetUserBanner200_Test_1(t *testing.T) {
 }
 
 func TestGetUserBanner200_Test_2(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 200 (from cache)",
 	})
@@ -41,8 +46,9 @@ func TestGetUserBanner200_Test_2(t *testing.T) {
 }
 
 func TestGetUserBanner200_Test_3(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 200 (admin token, is_active = true)",
 	})
@@ -56,8 +62,9 @@ func TestGetUserBanner200_Test_3(t *testing.T) {
 }
 
 func TestGetUserBanner200_Test_4(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 200 (admin token, is_active = false)",
 	})
@@ -72,8 +79,9 @@ func TestGetUserBanner200_Test_4(t *testing.T) {
 }
 
 func TestGetUserBanner400_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 400 (invalid feature and tag)",
 	})
@@ -87,8 +95,9 @@ func TestGetUserBanner400_Test_1(t *testing.T) {
 }
 
 func TestGetUserBanner400_Test_2(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 400 (invalid feature)",
 	})
@@ -102,8 +111,9 @@ func TestGetUserBanner400_Test_2(t *testing.T) {
 }
 
 func TestGetUserBanner400_Test_3(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 400 (invalid tag)",
 	})
@@ -117,8 +127,9 @@ func TestGetUserBanner400_Test_3(t *testing.T) {
 }
 
 func TestGetUserBanner401_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 401 (invalid token)",
 	})
@@ -132,8 +143,9 @@ func TestGetUserBanner401_Test_1(t *testing.T) {
 }
 
 func TestGetUserBanner403_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 403 (user_token to is_active = false banner)",
 	})
@@ -146,9 +158,38 @@ func TestGetUserBanner403_Test_1(t *testing.T) {
 		Expect().Status(http.StatusForbidden).JSON().IsEqual("Пользователь не имеет доступа")
 }
 
+func TestGetUserBanner403_Test_2(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /user_banner, status 403 (user_token, activation window not started)",
+	})
+
+	startsAt := time.Now().Add(24 * time.Hour)
+	exp.POST("/banner").WithJSON(models.BannerGetRequest{
+		TagIds:    []int32{1},
+		FeatureId: 2002,
+		Content: map[string]interface{}{
+			"title": "scheduled record from E2E test",
+		},
+		IsActive: true,
+		StartsAt: &startsAt,
+	}).WithHeader("token", "admin_token").
+		Expect().Status(http.StatusCreated)
+
+	exp.GET("/user_banner").
+		WithQuery("tag_id", 1).
+		WithQuery("feature_id", 2002).
+		WithQuery("use_last_revision", true).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
+
 func TestGetUserBanner404_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "GET /user_banner, status 404 (banner not found)",
 	})
@@ -2,18 +2,22 @@ package server_tests
 
 import (
 	"banner/models"
+	"banner/tests/testserver"
 	"github.com/gavv/httpexpect/v2"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestPatch200_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "PATCH /banner/{id}, status 200",
 	})
-	var featureID int32 = 1
+	// фича 1001 свободна: перенос баннера на занятые пары фича-тэг завершился бы ошибкой
+	var featureID int32 = 1001
 	exp.PATCH("/banner/{id}").WithPath("id", 2).
 		WithJSON(models.BannerIdDeleteRequest{
 			TagIds:    &[]int32{54, 85},
@@ -24,8 +28,9 @@ func TestPatch200_Test_1(t *testing.T) {
 }
 
 func TestPatch400_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "PATCH /banner/{id}, status 400",
 	})
@@ -40,8 +45,9 @@ func TestPatch400_Test_1(t *testing.T) {
 }
 
 func TestPatch401_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "PATCH /banner/{id}, status 401 (wrong_token)",
 	})
@@ -56,8 +62,9 @@ func TestPatch401_Test_1(t *testing.T) {
 }
 
 func TestPatch403_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "PATCH /banner/{id}, status 403 (user_token)",
 	})
@@ -72,8 +79,9 @@ func TestPatch403_Test_1(t *testing.T) {
 }
 
 func TestPatch404_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "PATCH /banner/{id}, status 404 invalid id",
 	})
@@ -86,3 +94,171 @@ func TestPatch404_Test_1(t *testing.T) {
 		WithHeader("token", "admin_token").
 		Expect().Status(http.StatusNotFound)
 }
+
+func TestPatch412_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 412 (stale If-Match)",
+	})
+	etag := exp.GET("/banner/{id}").WithPath("id", 5).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).Header("ETag").Raw()
+
+	isActive := true
+	exp.PATCH("/banner/{id}").WithPath("id", 5).
+		WithJSON(models.BannerIdDeleteRequest{IsActive: &isActive}).
+		WithHeader("token", "admin_token").
+		WithHeader("If-Match", etag).
+		Expect().Status(http.StatusOK)
+
+	exp.PATCH("/banner/{id}").WithPath("id", 5).
+		WithJSON(models.BannerIdDeleteRequest{IsActive: &isActive}).
+		WithHeader("token", "admin_token").
+		WithHeader("If-Match", etag).
+		Expect().Status(http.StatusPreconditionFailed)
+}
+
+func TestPatch400_Test_2(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 400 (ends_at before stored starts_at)",
+	})
+	startsAt := time.Now().Add(24 * time.Hour)
+	id := exp.POST("/banner").WithJSON(models.BannerGetRequest{
+		TagIds:    []int32{1},
+		FeatureId: 2003,
+		Content:   map[string]interface{}{"title": "scheduled record from E2E test"},
+		IsActive:  true,
+		StartsAt:  &startsAt,
+	}).WithHeader("token", "admin_token").
+		Expect().Status(http.StatusCreated).JSON().Object().Value("banner_id").Number().Raw()
+
+	exp.PATCH("/banner/{id}").WithPath("id", id).
+		WithJSON(map[string]interface{}{"ends_at": startsAt.Add(-time.Hour)}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestPatch200_Test_2(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 200 (null clears starts_at)",
+	})
+	startsAt := time.Now().Add(24 * time.Hour)
+	id := exp.POST("/banner").WithJSON(models.BannerGetRequest{
+		TagIds:    []int32{1},
+		FeatureId: 2003,
+		Content:   map[string]interface{}{"title": "scheduled record from E2E test"},
+		IsActive:  true,
+		StartsAt:  &startsAt,
+	}).WithHeader("token", "admin_token").
+		Expect().Status(http.StatusCreated).JSON().Object().Value("banner_id").Number().Raw()
+
+	exp.PATCH("/banner/{id}").WithPath("id", id).
+		WithJSON(map[string]interface{}{"starts_at": nil}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+
+	exp.GET("/user_banner").
+		WithQuery("tag_id", 1).
+		WithQuery("feature_id", 2003).
+		WithQuery("use_last_revision", true).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusOK)
+}
+
+func TestPatch412_Test_2(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 412 (weak If-Match)",
+	})
+	etag := exp.GET("/banner/{id}").WithPath("id", 5).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).Header("ETag").Raw()
+
+	isActive := true
+	exp.PATCH("/banner/{id}").WithPath("id", 5).
+		WithJSON(models.BannerIdDeleteRequest{IsActive: &isActive}).
+		WithHeader("token", "admin_token").
+		WithHeader("If-Match", "W/"+etag).
+		Expect().Status(http.StatusPreconditionFailed)
+}
+
+func TestPatch200_Test_3(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 200 (ETag of the new version)",
+	})
+	etag := exp.GET("/banner/{id}").WithPath("id", 5).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).Header("ETag").Raw()
+
+	isActive := true
+	next := exp.PATCH("/banner/{id}").WithPath("id", 5).
+		WithJSON(models.BannerIdDeleteRequest{IsActive: &isActive}).
+		WithHeader("token", "admin_token").
+		WithHeader("If-Match", etag).
+		Expect().Status(http.StatusOK).Header("ETag").NotEqual(etag).Raw()
+
+	// новый ETag подходит для следующего условного изменения без повторного чтения
+	exp.PATCH("/banner/{id}").WithPath("id", 5).
+		WithJSON(models.BannerIdDeleteRequest{IsActive: &isActive}).
+		WithHeader("token", "admin_token").
+		WithHeader("If-Match", next).
+		Expect().Status(http.StatusOK)
+}
+
+func TestPatch409_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 409 (feature and tag used by another banner)",
+	})
+
+	// пары фичи 2 заняты баннером 2
+	feature := int32(2)
+	exp.PATCH("/banner/{id}").WithPath("id", 1).
+		WithJSON(models.BannerIdDeleteRequest{FeatureId: &feature}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusConflict).
+		JSON().Object().Value("error").IsEqual("Пара фича-тэг баннера уже занята другим баннером")
+}
+
+func TestPatch200_Test_4(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "PATCH /banner/{id}, status 200 (deactivation hides cached banner)",
+	})
+
+	// баннер 999 активен и попадает в кэш
+	exp.GET("/user_banner").
+		WithQuery("feature_id", 999).
+		WithQuery("tag_id", 1).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusOK)
+
+	isActive := false
+	exp.PATCH("/banner/{id}").WithPath("id", 999).
+		WithJSON(models.BannerIdDeleteRequest{IsActive: &isActive}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+
+	exp.GET("/user_banner").
+		WithQuery("feature_id", 999).
+		WithQuery("tag_id", 1).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
@@ -2,14 +2,17 @@ package server_tests
 
 import (
 	"banner/models"
+	"banner/tests/testserver"
 	"github.com/gavv/httpexpect/v2"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestPostUserBanner200_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "POST /banner 1, status 200",
 	})
@@ -28,8 +31,9 @@ func TestPostUserBanner200_Test_1(t *testing.T) {
 }
 
 func TestPostUserBanner400_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "POST /banner, status 400 (tag = 0)",
 	})
@@ -47,8 +51,9 @@ func TestPostUserBanner400_Test_1(t *testing.T) {
 }
 
 func TestPostUserBanner400_Test_2(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "POST /banner, status 400 (feature = 0)",
 	})
@@ -66,8 +71,9 @@ func TestPostUserBanner400_Test_2(t *testing.T) {
 }
 
 func TestPostUserBanner400_Test_3(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "POST /banner, status 400 (banner already exists)",
 	})
@@ -84,9 +90,34 @@ func TestPostUserBanner400_Test_3(t *testing.T) {
 		Expect().Status(http.StatusBadRequest)
 }
 
+func TestPostUserBanner400_Test_4(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner, status 400 (ends_at before starts_at)",
+	})
+
+	startsAt := time.Now().Add(time.Hour)
+	endsAt := time.Now()
+	exp.POST("/banner").WithJSON(models.BannerGetRequest{
+		TagIds:    []int32{1, 2, 3, 4},
+		FeatureId: 2001,
+		Content: map[string]interface{}{
+			"title": "record from E2E test",
+			"text":  "expect status 400",
+		},
+		IsActive: true,
+		StartsAt: &startsAt,
+		EndsAt:   &endsAt,
+	}).WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
 func TestPostUserBanner401_Test_1(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "POST /banner, status 401 (wrong_token)",
 	})
@@ -104,8 +135,9 @@ func TestPostUserBanner401_Test_1(t *testing.T) {
 }
 
 func TestPostUserBanner403_Test_2(t *testing.T) {
+	t.Parallel()
 	exp := httpexpect.WithConfig(httpexpect.Config{
-		BaseURL:  "http://localhost:8080",
+		BaseURL:  testserver.Seeded(t).URL,
 		Reporter: httpexpect.NewAssertReporter(t),
 		TestName: "POST /banner, status 401 (user_token)",
 	})
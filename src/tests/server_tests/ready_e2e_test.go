@@ -0,0 +1,21 @@
+package server_tests
+
+import (
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestReady200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /ready, status 200",
+	})
+	exp.GET("/ready").
+		Expect().Status(http.StatusOK).JSON().Object().
+		HasValue("status", "ready")
+}
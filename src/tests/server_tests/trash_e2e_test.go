@@ -0,0 +1,74 @@
+package server_tests
+
+import (
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestTrash200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/trash, status 200",
+	})
+	exp.GET("/banner/trash").
+		WithQuery("limit", 5).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array()
+}
+
+func TestTrash403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/trash, status 403 (user_token)",
+	})
+	exp.GET("/banner/trash").
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestRestore200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/restore, status 200",
+	})
+	exp.DELETE("/banner/{id}").WithPath("id", 4).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusNoContent)
+
+	exp.POST("/banner/{id}/restore").WithPath("id", 4).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+}
+
+func TestRestore401_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/restore, status 401 (wrong_token)",
+	})
+	exp.POST("/banner/{id}/restore").WithPath("id", 4).
+		WithHeader("token", "wrong_token").
+		Expect().Status(http.StatusUnauthorized)
+}
+
+func TestRestore404_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/restore, status 404",
+	})
+	exp.POST("/banner/{id}/restore").WithPath("id", 100000000).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusNotFound)
+}
@@ -0,0 +1,124 @@
+package server_tests
+
+import (
+	"banner/models"
+	"banner/tests/testserver"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestVersions200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}/versions, status 200",
+	})
+	exp.GET("/banner/{id}/versions").WithPath("id", 3).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK).JSON().Array()
+}
+
+func TestVersions401_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}/versions, status 401 (wrong_token)",
+	})
+	exp.GET("/banner/{id}/versions").WithPath("id", 3).
+		WithHeader("token", "wrong_token").
+		Expect().Status(http.StatusUnauthorized)
+}
+
+func TestVersions403_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}/versions, status 403 (user_token)",
+	})
+	exp.GET("/banner/{id}/versions").WithPath("id", 3).
+		WithHeader("token", "user_token").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestVersions404_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "GET /banner/{id}/versions, status 404",
+	})
+	exp.GET("/banner/{id}/versions").WithPath("id", 100000000).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestRestoreVersion200_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/versions/{version}/restore, status 200",
+	})
+	exp.POST("/banner/{id}/versions/{version}/restore").
+		WithPath("id", 3).
+		WithPath("version", 1).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+}
+
+func TestRestoreVersion400_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/versions/{version}/restore, status 400",
+	})
+	exp.POST("/banner/{id}/versions/{version}/restore").
+		WithPath("id", 3).
+		WithPath("version", -1).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestRestoreVersion404_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/versions/{version}/restore, status 404",
+	})
+	exp.POST("/banner/{id}/versions/{version}/restore").
+		WithPath("id", 3).
+		WithPath("version", 100000).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestRestoreVersion409_Test_1(t *testing.T) {
+	t.Parallel()
+	exp := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testserver.Seeded(t).URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		TestName: "POST /banner/{id}/versions/{version}/restore, status 409 (pair is used by another banner)",
+	})
+	exp.PATCH("/banner/{id}").WithPath("id", 3).
+		WithJSON(map[string]interface{}{"feature_id": 2000}).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusOK)
+	exp.POST("/banner").WithJSON(models.BannerGetRequest{
+		TagIds:    []int32{1},
+		FeatureId: 3,
+		IsActive:  true,
+	}).WithHeader("token", "admin_token").
+		Expect().Status(http.StatusCreated)
+	exp.POST("/banner/{id}/versions/{version}/restore").
+		WithPath("id", 3).
+		WithPath("version", 1).
+		WithHeader("token", "admin_token").
+		Expect().Status(http.StatusConflict)
+}
@@ -1,6 +1,7 @@
 package stress_tests
 
 import (
+	"banner/tests/testserver"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -10,11 +11,11 @@ import (
 	"time"
 )
 
-var timeGet int64
+var timeGet atomic.Int64
 
-func sendGetUserBannerRequest(args string) string {
+func sendGetUserBannerRequest(baseURL string, args string) string {
 	start := time.Now()
-	req, err := http.NewRequest("GET", "http://localhost:8080/user_banner"+args, nil)
+	req, err := http.NewRequest("GET", baseURL+"/user_banner"+args, nil)
 	if err != nil {
 		fmt.Println("Ошибка при создании запроса:", err)
 		return ""
@@ -23,18 +24,20 @@ func sendGetUserBannerRequest(args string) string {
 	req.Header.Set("Content-Type", "application/json")
 	client := &http.Client{}
 	resp, err := client.Do(req)
-	if err == nil {
-		defer resp.Body.Close()
+	if err != nil {
+		return "ERROR"
 	}
+	defer resp.Body.Close()
 	end := time.Now()
 	//pretty.Print(resp.Body)
-	timeGet += end.Sub(start).Milliseconds()
+	timeGet.Add(end.Sub(start).Milliseconds())
 	//}
 	//chanData <- value.BannerId
 	return resp.Status
 }
 
 func TestGetUserBanner(t *testing.T) {
+	baseURL := testserver.Seeded(t).URL
 	n := 1000
 	args := make([]string, 0, n)
 	for i := 1; i < n+1; i++ {
@@ -45,14 +48,14 @@ func TestGetUserBanner(t *testing.T) {
 	var mu sync.Mutex
 	total := len(args)
 	success := atomic.Int32{}
-	timeGet = 0
+	timeGet.Store(0)
 	for _, arg := range args {
 		wg.Add(1)
 		time.Sleep(1 * time.Millisecond)
 		go func(arg string, success *atomic.Int32) {
 			defer wg.Done()
 			time.Sleep(1 * time.Millisecond)
-			res := sendGetUserBannerRequest(arg)
+			res := sendGetUserBannerRequest(baseURL, arg)
 			if res == "200 OK" {
 				mu.Lock()
 				success.Add(1)
@@ -66,8 +69,8 @@ func TestGetUserBanner(t *testing.T) {
 	}
 	wg.Wait()
 	fmt.Printf("Выполнено %d запросов \n", total)
-	fmt.Printf("Время выполнения %d запросов %vms\n", total, timeGet)
-	fmt.Printf("Среднее время выполнения одного запроса %vms\n", float64(timeGet)/1000)
+	fmt.Printf("Время выполнения %d запросов %vms\n", total, timeGet.Load())
+	fmt.Printf("Среднее время выполнения одного запроса %vms\n", float64(timeGet.Load())/1000)
 	numSuccess := success.Load()
 	fmt.Printf("Успешных вставок: %d = %v %% \n\n", numSuccess, float64(numSuccess)*100./float64(total))
 
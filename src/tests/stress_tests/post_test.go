@@ -1,6 +1,7 @@
 package stress_tests
 
 import (
+	"banner/tests/testserver"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -12,15 +13,15 @@ import (
 	"time"
 )
 
-var timePost int64
+var timePost atomic.Int64
 
 //type value struct {
 //	BannerId int `json:"banner_id"`
 //}
 
-func sendPostRequest(banner []byte) string {
+func sendPostRequest(baseURL string, banner []byte) string {
 	start := time.Now()
-	req, err := http.NewRequest("POST", "http://localhost:8080/banner", bytes.NewBuffer(banner))
+	req, err := http.NewRequest("POST", baseURL+"/banner", bytes.NewBuffer(banner))
 	if err != nil {
 		fmt.Println("Ошибка при создании запроса:", err)
 		return ""
@@ -35,11 +36,12 @@ func sendPostRequest(banner []byte) string {
 		return "ERROR"
 	}
 	end := time.Now()
-	timePost += end.Sub(start).Milliseconds()
+	timePost.Add(end.Sub(start).Milliseconds())
 	return resp.Status
 }
 
 func TestAdd(t *testing.T) {
+	baseURL := testserver.Start(t).URL
 	n := 1000
 	banners := make([]map[string]interface{}, 0, n)
 	tags := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
@@ -65,7 +67,7 @@ func TestAdd(t *testing.T) {
 	var mu sync.Mutex
 	total := len(banners)
 	success := atomic.Int32{}
-	timePost = 0
+	timePost.Store(0)
 	for _, banner := range banners {
 		wg.Add(1)
 		time.Sleep(1 * time.Millisecond)
@@ -73,7 +75,7 @@ func TestAdd(t *testing.T) {
 			defer wg.Done()
 			data, _ := json.Marshal(banner)
 			time.Sleep(1 * time.Millisecond)
-			res := sendPostRequest(data)
+			res := sendPostRequest(baseURL, data)
 			if res == "201 Created" {
 				mu.Lock()
 				success.Add(1)
@@ -89,8 +91,8 @@ func TestAdd(t *testing.T) {
 	}
 	wg.Wait()
 	fmt.Printf("Выполнено %d запросов на вставку \n", total)
-	fmt.Printf("Время выполнения %d запросов %vms\n", total, timePost)
-	fmt.Printf("Среднее время выполнения одного запроса %vms\n", float64(timePost)/1000)
+	fmt.Printf("Время выполнения %d запросов %vms\n", total, timePost.Load())
+	fmt.Printf("Среднее время выполнения одного запроса %vms\n", float64(timePost.Load())/1000)
 	numSuccess := success.Load()
 	fmt.Printf("Успешных запросов: %d = %v %% \n\n", numSuccess, float64(numSuccess)*100./float64(total))
 
@@ -0,0 +1,95 @@
+// Package testserver запускает сервис баннеров внутри процесса теста поверх хранилища в памяти,
+// чтобы E2E и нагрузочные тесты не зависели от запущенного сервера и базы данных
+package testserver
+
+import (
+	"banner/models"
+	openapi "banner/restapi"
+	"context"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// Тестовые баннеры: баннер с id = i имеет фичу i и тэги 1..Tags.
+// Все баннеры активны, кроме баннера с фичей Features
+const (
+	Features = 1000
+	Tags     = 10
+)
+
+// env - окружение сервиса в тестах. Задается принудительно, чтобы переменные разработчика
+// (например, STORAGE_BACKEND=postgres) не подключали тесты к внешним сервисам
+var env = map[string]string{
+	"LOG_PATH":                  os.DevNull,
+	"STORAGE_BACKEND":           "memory",
+	"CACHE_BACKEND":             "memory",
+	"CACHE_EXPIRATION":          "5m",
+	"CACHE_HARD_EXPIRATION":     "5m",
+	"CACHE_CLEANUP_INTERVAL":    "6m",
+	"CACHE_NEGATIVE_EXPIRATION": "10s",
+	"CACHE_WARMUP":              "off",
+}
+
+var setupEnv sync.Once
+
+type Server struct {
+	URL     string
+	service *openapi.DefaultAPIService
+}
+
+// Start запускает сервер без баннеров. Сервер останавливается после завершения теста
+func Start(t testing.TB) *Server {
+	t.Helper()
+	setupEnv.Do(func() {
+		for key, value := range env {
+			os.Setenv(key, value)
+		}
+	})
+	service := openapi.NewDefaultAPIService().(*openapi.DefaultAPIService)
+	if err := service.WarmUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(openapi.NewRouter(openapi.NewDefaultAPIController(service)))
+	t.Cleanup(func() {
+		server.Close()
+		if err := service.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return &Server{URL: server.URL, service: service}
+}
+
+// Seeded запускает сервер с тестовыми баннерами
+func Seeded(t testing.TB) *Server {
+	t.Helper()
+	s := Start(t)
+	s.Seed(t)
+	return s
+}
+
+// Seed создает тестовые баннеры напрямую в хранилище, минуя HTTP
+func (s *Server) Seed(t testing.TB) {
+	t.Helper()
+	tags := make([]int32, 0, Tags)
+	for tag := int32(1); tag <= Tags; tag++ {
+		tags = append(tags, tag)
+	}
+	for feature := int32(1); feature <= Features; feature++ {
+		_, err := s.service.Storage.Insert(&models.InsertData{
+			Feature:  feature,
+			TagIds:   tags,
+			IsActive: feature != Features,
+			Content: models.JSONMap{
+				"title": "some_title111",
+				"text":  "some_text",
+				"url":   "some_url",
+			},
+			AuditInfo: models.AuditInfo{Actor: "admin"},
+		})
+		if err != nil {
+			t.Fatalf("can't seed banner with feature %d: %v", feature, err)
+		}
+	}
+}